@@ -0,0 +1,43 @@
+package api
+
+import (
+	"github.com/cloudimpl/next-coder-sdk/polycode"
+	"github.com/cloudimpl/next-coder-sdk/rawcontext"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// RateLimit returns a middleware that rejects requests over config with 429 and a Retry-After header.
+// keyFn picks the rate limit key for a request, e.g. the caller id or client ip.
+func RateLimit(name string, config polycode.RateLimit, keyFn func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawCtx, err := rawcontext.FromContext(c.Request.Context())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check rate limit: " + err.Error(),
+			})
+			return
+		}
+
+		limiter := polycode.NewRateLimiter(rawCtx, name, config)
+		res, err := limiter.Check(keyFn(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check rate limit: " + err.Error(),
+			})
+			return
+		}
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "Rate limit exceeded",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/cloudimpl/next-coder-sdk/apicontext"
+	"github.com/cloudimpl/next-coder-sdk/polycode"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// EmitSignal returns a handler that emits signalName to the workflow correlated with the value of
+// the keyParam path parameter, using the JSON body as the signal value. authorize is called with the
+// caller auth context and the correlation key and must return nil for the signal to be emitted.
+// authorize is required, EmitSignal panics when it is nil.
+func EmitSignal(signalName string, keyParam string, authorize func(polycode.AuthContext, string) error) func(c *gin.Context) {
+	if authorize == nil {
+		panic("api: EmitSignal requires an authorize func for signal " + signalName)
+	}
+
+	return func(c *gin.Context) {
+		apiCtx, err := apicontext.FromContext(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to emit signal: " + err.Error(),
+			})
+			return
+		}
+
+		key := c.Param(keyParam)
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Missing correlation key",
+			})
+			return
+		}
+
+		if err = authorize(apiCtx.AuthContext(), key); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		var data any
+		if err = c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request",
+			})
+			return
+		}
+
+		signal := apiCtx.Signal(signalName)
+		if err = signal.EmitByKey(key, data); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to emit signal: " + err.Error(),
+			})
+			return
+		}
+
+		c.Status(http.StatusAccepted)
+	}
+}
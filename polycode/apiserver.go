@@ -14,6 +14,8 @@ func startApiServer() {
 	r.GET("/v1/health", invokeHealthCheck)
 	r.POST("/v1/invoke/api", invokeApiHandler)
 	r.POST("/v1/invoke/service", invokeServiceHandler)
+	r.POST("/v1/invoke/query", invokeQueryHandler)
+	r.POST("/v1/invoke/cancel", invokeCancelHandler)
 
 	// Start the Gin server
 	err := r.Run(fmt.Sprintf(":%d", GetClientEnv().AppPort))
@@ -61,3 +63,31 @@ func invokeServiceHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, output)
 }
+
+func invokeQueryHandler(c *gin.Context) {
+	taskLogger := CreateLogger("task")
+
+	var input QueryStartEvent
+	var output QueryCompleteEvent
+
+	taskLogger.Info().Msg("query task started")
+	if err := c.ShouldBindJSON(&input); err != nil {
+		output = QueryCompleteEvent{IsError: true, Error: ErrInternal.Wrap(err)}
+		taskLogger.Error().Msg(fmt.Sprintf("query task failed %s", err.Error()))
+	} else {
+		output = runQuery(c, taskLogger, input)
+		taskLogger.Info().Msg("query task success")
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+func invokeCancelHandler(c *gin.Context) {
+	var input TaskCancelEvent
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cancelled": runningTasks.cancel(input.TaskId)})
+}
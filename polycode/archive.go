@@ -0,0 +1,314 @@
+package polycode
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+const archiveListPageSize = 100
+
+// downloadClient has no timeout since archive entries can be arbitrarily large
+var downloadClient = &http.Client{}
+
+// ExportArchive bundles every file under the folder into a zip or tar.gz archive and
+// saves it to destPath. Entries are streamed one by one through a local temp file.
+func (f Folder) ExportArchive(format ArchiveFormat, destPath string) error {
+	if format != ArchiveZip && format != ArchiveTarGz {
+		return ErrUnsupportedArchive.With(format)
+	}
+
+	tmp, err := os.CreateTemp("", "polycode-archive-*")
+	if err != nil {
+		fmt.Printf("failed to create temp file: %s\n", err.Error())
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if format == ArchiveZip {
+		err = f.writeZip(tmp)
+	} else {
+		err = f.writeTarGz(tmp)
+	}
+	if err != nil {
+		fmt.Printf("failed to write archive: %s\n", err.Error())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return newFileStore(f.client, f.sessionId, nil).Upload(destPath, tmp.Name())
+}
+
+func (f Folder) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	err := f.walk(func(name string, file ListFileResponse) error {
+		header := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: file.LastModified,
+		}
+
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		return f.copyFile(entry, name)
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func (f Folder) writeTarGz(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := f.walk(func(name string, file ListFileResponse) error {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     file.Size,
+			Mode:     0644,
+			ModTime:  file.LastModified,
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		return f.copyFile(tw, name)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// walk calls fn for every file under the folder with its name relative to the folder
+func (f Folder) walk(fn func(name string, file ListFileResponse) error) error {
+	store := newFileStore(f.client, f.sessionId, nil)
+	// list with a trailing separator so that sibling folders sharing the name as a prefix are excluded
+	prefix := strings.TrimSuffix(f.name, "/") + "/"
+
+	var nextToken *string
+	for {
+		page, err := store.List(prefix, archiveListPageSize, nextToken)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range page.Files {
+			name := strings.TrimPrefix(file.Key, "/")
+			if name == "" || strings.HasSuffix(name, "/") {
+				continue
+			}
+
+			if err = fn(name, file); err != nil {
+				return err
+			}
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == nil {
+			return nil
+		}
+		nextToken = page.NextContinuationToken
+	}
+}
+
+func (f Folder) copyFile(w io.Writer, name string) error {
+	link, err := newFileStore(f.client, f.sessionId, nil).GetDownloadLink(f.name + "/" + name)
+	if err != nil {
+		return err
+	}
+
+	resp, err := downloadClient.Get(link)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http error, status: %v", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// ExtractArchive unpacks the zip or tar.gz archive at srcPath into destFolder. The format is
+// detected from the file extension and entries that escape the folder are rejected.
+func (d FileStore) ExtractArchive(srcPath string, destFolder string) error {
+	format, err := archiveFormatFromPath(srcPath)
+	if err != nil {
+		return err
+	}
+
+	link, err := d.GetDownloadLink(srcPath)
+	if err != nil {
+		return err
+	}
+
+	resp, err := downloadClient.Get(link)
+	if err != nil {
+		fmt.Printf("failed to download archive: %s\n", err.Error())
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http error, status: %v", resp.Status)
+	}
+
+	folder := d.Folder(destFolder)
+	if format == ArchiveZip {
+		return extractZip(resp.Body, folder)
+	}
+	return extractTarGz(resp.Body, folder)
+}
+
+func extractZip(r io.Reader, folder Folder) error {
+	// zip needs random access to the central directory, so spool the archive to disk first
+	tmp, err := os.CreateTemp("", "polycode-archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(tmp, size)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		name, err := sanitizeArchiveEntry(file.Name)
+		if err != nil {
+			return err
+		}
+
+		entry, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		err = uploadArchiveEntry(folder, name, entry)
+		entry.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractTarGz(r io.Reader, folder Folder) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, err := sanitizeArchiveEntry(header.Name)
+		if err != nil {
+			return err
+		}
+
+		if err = uploadArchiveEntry(folder, name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func uploadArchiveEntry(folder Folder, name string, r io.Reader) error {
+	tmp, err := os.CreateTemp("", "polycode-entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return folder.Upload(name, tmp.Name())
+}
+
+func sanitizeArchiveEntry(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") {
+		return "", ErrInvalidArchiveEntry.With(name)
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidArchiveEntry.With(name)
+	}
+
+	return cleaned, nil
+}
+
+func archiveFormatFromPath(p string) (ArchiveFormat, error) {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTarGz, nil
+	default:
+		return "", ErrUnsupportedArchive.With(path.Ext(p))
+	}
+}
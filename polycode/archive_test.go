@@ -0,0 +1,75 @@
+package polycode
+
+import "testing"
+
+func TestSanitizeArchiveEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "file.txt", want: "file.txt"},
+		{name: "a/b/c.txt", want: "a/b/c.txt"},
+		{name: "a/./b.txt", want: "a/b.txt"},
+		{name: "a/../b.txt", want: "b.txt"},
+		{name: "a\\b\\c.txt", want: "a/b/c.txt"},
+		{name: "./a.txt", want: "a.txt"},
+		{name: ".", wantErr: true},
+		{name: "", wantErr: true},
+		{name: "..", wantErr: true},
+		{name: "../a.txt", wantErr: true},
+		{name: "a/../../b.txt", wantErr: true},
+		{name: "..\\a.txt", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+		{name: "\\etc\\passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := sanitizeArchiveEntry(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("sanitizeArchiveEntry(%q) = %q, want error", tt.name, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("sanitizeArchiveEntry(%q) unexpected error: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("sanitizeArchiveEntry(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    ArchiveFormat
+		wantErr bool
+	}{
+		{path: "out.zip", want: ArchiveZip},
+		{path: "dir/OUT.ZIP", want: ArchiveZip},
+		{path: "out.tar.gz", want: ArchiveTarGz},
+		{path: "out.tgz", want: ArchiveTarGz},
+		{path: "out.tar", wantErr: true},
+		{path: "out.gz", wantErr: true},
+		{path: "zip", wantErr: true},
+		{path: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := archiveFormatFromPath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("archiveFormatFromPath(%q) = %q, want error", tt.path, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("archiveFormatFromPath(%q) unexpected error: %v", tt.path, err)
+		} else if got != tt.want {
+			t.Errorf("archiveFormatFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package polycode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// taskRegistry tracks the cancel functions of tasks running in this process, keyed by task id
+type taskRegistry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+var runningTasks = &taskRegistry{cancels: make(map[string]context.CancelFunc)}
+
+// start derives a context for the task that is cancelled only by a cancellation request, not when the
+// request carrying the task ends, so cancellation scopes never fire on completion or suspension.
+// The returned func must be called when the task exits to unregister it.
+func (t *taskRegistry) start(ctx context.Context, taskId string, cancelled bool) (context.Context, func()) {
+	taskCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if cancelled {
+		cancel()
+	}
+
+	t.mu.Lock()
+	t.cancels[taskId] = cancel
+	t.mu.Unlock()
+
+	return taskCtx, func() {
+		t.mu.Lock()
+		delete(t.cancels, taskId)
+		t.mu.Unlock()
+	}
+}
+
+func (t *taskRegistry) cancel(taskId string) bool {
+	t.mu.Lock()
+	cancel := t.cancels[taskId]
+	t.mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+
+	cancel()
+	return true
+}
+
+func cancelTask(sessionId string, serviceClient *ServiceClient, taskId string) error {
+	req := CancelTaskRequest{
+		TaskId: taskId,
+	}
+
+	err := serviceClient.CancelTask(sessionId, req)
+	if err != nil {
+		fmt.Printf("client: cancel task error: %v\n", err)
+		return wrapTaskError(err)
+	}
+
+	return nil
+}
+
+// Cancel requests cancellation of the task behind the future, it is a no-op once the task completed
+func (f *Future) Cancel() error {
+	if f.done.Load() {
+		return nil
+	}
+
+	return cancelTask(f.sessionId, f.serviceClient, f.taskId)
+}
+
+// CancellationScope groups child tasks so they can be cancelled together. The scope is
+// cancelled automatically when the parent workflow is cancelled.
+type CancellationScope struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	futures []*Future
+}
+
+func newCancellationScope(parent context.Context) *CancellationScope {
+	ctx, cancel := context.WithCancel(parent)
+	scope := &CancellationScope{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	context.AfterFunc(ctx, func() {
+		_ = scope.cancelFutures()
+	})
+	return scope
+}
+
+// Track adds the future to the scope and returns it for chaining
+func (s *CancellationScope) Track(f *Future) *Future {
+	s.mu.Lock()
+	s.futures = append(s.futures, f)
+	s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		_ = f.Cancel()
+	}
+	return f
+}
+
+// Cancel cancels every tracked task that has not completed yet
+func (s *CancellationScope) Cancel() error {
+	s.cancel()
+	return s.cancelFutures()
+}
+
+func (s *CancellationScope) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+func (s *CancellationScope) Err() error {
+	return s.ctx.Err()
+}
+
+func (s *CancellationScope) cancelFutures() error {
+	s.mu.Lock()
+	futures := s.futures
+	s.futures = nil
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, f := range futures {
+		if err := f.Cancel(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
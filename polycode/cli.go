@@ -25,9 +25,15 @@ func getAppInfo(filePath string) error {
 		return err
 	}
 
+	triggers, err := loadFileTriggers()
+	if err != nil {
+		return err
+	}
+
 	req := StartAppRequest{
-		Services: services,
-		Routes:   loadRoutes(),
+		Services:     services,
+		Routes:       loadRoutes(),
+		FileTriggers: triggers,
 	}
 
 	output, err := json.Marshal(req)
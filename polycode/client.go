@@ -22,11 +22,12 @@ type TaskStatus int8
 type DbAction string
 
 type StartAppRequest struct {
-	AppName    string               `json:"appName"`
-	AppPort    uint                 `json:"appPort"`
-	Services   []ServiceDescription `json:"services"`
-	ApiHandler string               `json:"apiHandler"`
-	Routes     []RouteData          `json:"routes"`
+	AppName      string               `json:"appName"`
+	AppPort      uint                 `json:"appPort"`
+	Services     []ServiceDescription `json:"services"`
+	ApiHandler   string               `json:"apiHandler"`
+	Routes       []RouteData          `json:"routes"`
+	FileTriggers []FileTrigger        `json:"fileTriggers"`
 }
 
 type ExecServiceRequest struct {
@@ -37,6 +38,7 @@ type ExecServiceRequest struct {
 	Method        string            `json:"method"`
 	Options       TaskOptions       `json:"options"`
 	FireAndForget bool              `json:"fireAndForget"`
+	ScheduleAt    int64             `json:"scheduleAt"`
 	Headers       map[string]string `json:"headers"`
 	Input         any               `json:"input"`
 }
@@ -47,6 +49,7 @@ type ExecAppRequest struct {
 	Method        string      `json:"method"`
 	Options       TaskOptions `json:"options"`
 	FireAndForget bool        `json:"fireAndForget"`
+	ScheduleAt    int64       `json:"scheduleAt"`
 	Input         any         `json:"input"`
 }
 
@@ -61,14 +64,103 @@ type ExecAppExtendedRequest struct {
 }
 
 type ExecServiceResponse struct {
-	IsAsync bool  `json:"isAsync"`
-	Output  any   `json:"output"`
-	IsError bool  `json:"isError"`
-	Error   Error `json:"error"`
+	IsAsync bool   `json:"isAsync"`
+	TaskId  string `json:"taskId"`
+	Output  any    `json:"output"`
+	IsError bool   `json:"isError"`
+	Error   Error  `json:"error"`
 }
 
 type ExecAppResponse struct {
-	IsAsync bool  `json:"isAsync"`
+	IsAsync bool   `json:"isAsync"`
+	TaskId  string `json:"taskId"`
+	Output  any    `json:"output"`
+	IsError bool   `json:"isError"`
+	Error   Error  `json:"error"`
+}
+
+// ExecAsyncResponse is returned when a task is started without blocking the caller.
+// IsCompleted is set when the task already finished, e.g. while replaying a workflow.
+type ExecAsyncResponse struct {
+	TaskId      string `json:"taskId"`
+	IsCompleted bool   `json:"isCompleted"`
+	Output      any    `json:"output"`
+	IsError     bool   `json:"isError"`
+	Error       Error  `json:"error"`
+}
+
+type AwaitTasksRequest struct {
+	TaskIds []string `json:"taskIds"`
+	WaitAll bool     `json:"waitAll"`
+}
+
+type TaskResult struct {
+	TaskId      string `json:"taskId"`
+	IsCompleted bool   `json:"isCompleted"`
+	Output      any    `json:"output"`
+	IsError     bool   `json:"isError"`
+	Error       Error  `json:"error"`
+}
+
+type AwaitTasksResponse struct {
+	IsAsync bool         `json:"isAsync"`
+	Results []TaskResult `json:"results"`
+}
+
+// GetVersionRequest records MaxVersion for ChangeId on first execution, replays return the recorded
+// version or DefaultVersion when the execution passed this point before the change existed
+type GetVersionRequest struct {
+	ChangeId   string `json:"changeId"`
+	MinVersion int    `json:"minVersion"`
+	MaxVersion int    `json:"maxVersion"`
+}
+
+type GetVersionResponse struct {
+	Version int `json:"version"`
+}
+
+type ListDeadLettersRequest struct {
+	Destination       string  `json:"destination"`
+	Limit             int32   `json:"limit"`
+	ContinuationToken *string `json:"continuationToken"`
+}
+
+type ListDeadLettersResponse struct {
+	DeadLetters           []DeadLetter `json:"deadLetters"`
+	NextContinuationToken *string      `json:"nextContinuationToken"`
+}
+
+type DeadLetterRequest struct {
+	Destination string `json:"destination"`
+	Id          string `json:"id"`
+}
+
+type GetDeadLetterResponse struct {
+	Exist      bool       `json:"exist"`
+	DeadLetter DeadLetter `json:"deadLetter"`
+}
+
+// ContinueAsNewRequest completes the current task and starts the same service method again
+// with Input on the same partition key
+type ContinueAsNewRequest struct {
+	Input any `json:"input"`
+}
+
+type CancelTaskRequest struct {
+	TaskId string `json:"taskId"`
+}
+
+type QueryWorkflowRequest struct {
+	EnvId        string `json:"envId"`
+	Service      string `json:"service"`
+	TenantId     string `json:"tenantId"`
+	PartitionKey string `json:"partitionKey"`
+	TaskId       string `json:"taskId"`
+	Query        string `json:"query"`
+	Input        any    `json:"input"`
+}
+
+type QueryWorkflowResponse struct {
 	Output  any   `json:"output"`
 	IsError bool  `json:"isError"`
 	Error   Error `json:"error"`
@@ -95,23 +187,27 @@ type ExecApiResponse struct {
 	Error    Error       `json:"error"`
 }
 
+// ExecFuncRequest looks up a memo by position, Key is checked against the recorded key on replay
 type ExecFuncRequest struct {
-	Input any `json:"input"`
+	Key   string `json:"key"`
+	Input any    `json:"input"`
 }
 
 type ExecFuncResult struct {
-	Input   any   `json:"input"`
-	Output  any   `json:"output"`
-	IsError bool  `json:"isError"`
-	Error   Error `json:"error"`
+	Key     string `json:"key"`
+	Input   any    `json:"input"`
+	Output  any    `json:"output"`
+	IsError bool   `json:"isError"`
+	Error   Error  `json:"error"`
 }
 
 type ExecFuncResponse struct {
-	IsAsync     bool  `json:"isAsync"`
-	IsCompleted bool  `json:"isCompleted"`
-	Output      any   `json:"output"`
-	IsError     bool  `json:"isError"`
-	Error       Error `json:"error"`
+	IsAsync     bool   `json:"isAsync"`
+	IsCompleted bool   `json:"isCompleted"`
+	Key         string `json:"key"`
+	Output      any    `json:"output"`
+	IsError     bool   `json:"isError"`
+	Error       Error  `json:"error"`
 }
 
 // PutRequest represents the JSON structure for put operations
@@ -154,6 +250,7 @@ type GetFileRequest struct {
 type GetUploadLinkRequest struct {
 	Key      string `json:"key"`
 	TempFile bool   `json:"tempFile"`
+	TTL      int64  `json:"TTL"`
 }
 
 // GetFileResponse represents the JSON structure for get file response
@@ -169,6 +266,7 @@ type GetLinkResponse struct {
 type PutFileRequest struct {
 	Key      string `json:"key"`
 	TempFile bool   `json:"tempFile"`
+	TTL      int64  `json:"TTL"`
 	Content  string `json:"content"`
 	FilePath string `json:"filePath"`
 }
@@ -183,6 +281,21 @@ type RenameFileRequest struct {
 	TempFile bool   `json:"tempFile"`
 }
 
+type PromoteFileRequest struct {
+	TempKey string `json:"tempKey"`
+	Key     string `json:"key"`
+}
+
+type ListTempFilesRequest struct {
+}
+
+type ListTempFilesResponse struct {
+	Files []ListFileResponse `json:"files"`
+}
+
+type PurgeTempFilesRequest struct {
+}
+
 type CreateFolderRequest struct {
 	Folder string `json:"folder"`
 }
@@ -208,12 +321,41 @@ type ListFilePageResponse struct {
 	IsTruncated           bool               `json:"isTruncated"`
 }
 
+type ListFileVersionsRequest struct {
+	Key string `json:"key"`
+}
+
+// FileVersion is one prior revision of a file, Author is the task that saved it
+type FileVersion struct {
+	VersionId    string            `json:"versionId"`
+	Size         int64             `json:"size"`
+	LastModified time.Time         `json:"lastModified"`
+	IsLatest     bool              `json:"isLatest"`
+	Author       CallerContextMeta `json:"author"`
+}
+
+type ListFileVersionsResponse struct {
+	Versions []FileVersion `json:"versions"`
+}
+
+type GetFileVersionRequest struct {
+	Key       string `json:"key"`
+	VersionId string `json:"versionId"`
+}
+
+type RestoreFileVersionRequest struct {
+	Key       string `json:"key"`
+	VersionId string `json:"versionId"`
+}
+
+// SignalEmitRequest targets TaskId, or the task awaiting the signal with CorrelationKey when TaskId is empty
 type SignalEmitRequest struct {
-	TaskId     string `json:"taskId"`
-	SignalName string `json:"signalName"`
-	Output     any    `json:"output"`
-	IsError    bool   `json:"isError"`
-	Error      Error  `json:"error"`
+	TaskId         string `json:"taskId"`
+	CorrelationKey string `json:"correlationKey"`
+	SignalName     string `json:"signalName"`
+	Output         any    `json:"output"`
+	IsError        bool   `json:"isError"`
+	Error          Error  `json:"error"`
 }
 
 type RealtimeEventEmitRequest struct {
@@ -221,15 +363,67 @@ type RealtimeEventEmitRequest struct {
 	Input   any    `json:"input"`
 }
 
+// SignalWaitRequest waits for SignalName, or for whichever of SignalNames and TimerIds fires first
+// CorrelationKey applies to every signal in the request.
 type SignalWaitRequest struct {
-	SignalName string `json:"signalName"`
+	SignalName     string   `json:"signalName"`
+	SignalNames    []string `json:"signalNames"`
+	TimerIds       []string `json:"timerIds"`
+	CorrelationKey string   `json:"correlationKey"`
 }
 
+// SignalWaitResponse reports the source that fired, TimerId is set when a timer won the race
 type SignalWaitResponse struct {
-	IsAsync bool  `json:"isAsync"`
-	Output  any   `json:"output"`
-	IsError bool  `json:"isError"`
-	Error   Error `json:"error"`
+	IsAsync    bool   `json:"isAsync"`
+	SignalName string `json:"signalName"`
+	TimerId    string `json:"timerId"`
+	Output     any    `json:"output"`
+	IsError    bool   `json:"isError"`
+	Error      Error  `json:"error"`
+}
+
+// SignalReceiveRequest takes the next queued value of a signal channel, Block waits for one
+type SignalReceiveRequest struct {
+	SignalName     string `json:"signalName"`
+	CorrelationKey string `json:"correlationKey"`
+	Block          bool   `json:"block"`
+}
+
+type SignalReceiveResponse struct {
+	IsAsync  bool  `json:"isAsync"`
+	HasValue bool  `json:"hasValue"`
+	Output   any   `json:"output"`
+	IsError  bool  `json:"isError"`
+	Error    Error `json:"error"`
+}
+
+type SignalChannelLenRequest struct {
+	SignalName     string `json:"signalName"`
+	CorrelationKey string `json:"correlationKey"`
+}
+
+type SignalChannelLenResponse struct {
+	Length int `json:"length"`
+}
+
+// StartTimerRequest registers a durable timer, FireAt is in unix milliseconds
+type StartTimerRequest struct {
+	FireAt int64 `json:"fireAt"`
+}
+
+type StartTimerResponse struct {
+	TimerId string `json:"timerId"`
+	FireAt  int64  `json:"fireAt"`
+	IsFired bool   `json:"isFired"`
+}
+
+type AwaitTimerRequest struct {
+	TimerId string `json:"timerId"`
+}
+
+type AwaitTimerResponse struct {
+	IsAsync bool `json:"isAsync"`
+	IsFired bool `json:"isFired"`
 }
 
 type GetMetaDataRequest struct {
@@ -247,12 +441,37 @@ type IncrementCounterRequest struct {
 }
 
 type AcquireLockRequest struct {
-	Key string `json:"key"`
-	TTL int64  `json:"TTL"`
+	Key     string   `json:"key"`
+	Owner   string   `json:"owner"`
+	Mode    LockMode `json:"mode"`
+	Permits uint32   `json:"permits"`
+	TTL     int64    `json:"TTL"`
+}
+
+type AcquireLockResponse struct {
+	Acquired     bool   `json:"acquired"`
+	FencingToken uint64 `json:"fencingToken"`
+}
+
+type RenewLockRequest struct {
+	Key          string `json:"key"`
+	Owner        string `json:"owner"`
+	FencingToken uint64 `json:"fencingToken"`
+	TTL          int64  `json:"TTL"`
+}
+
+type RenewLockResponse struct {
+	Renewed bool `json:"renewed"`
 }
 
 type ReleaseLockRequest struct {
-	Key string `json:"key"`
+	Key          string `json:"key"`
+	Owner        string `json:"owner"`
+	FencingToken uint64 `json:"fencingToken"`
+}
+
+type ReleaseLockResponse struct {
+	Released bool `json:"released"`
 }
 
 type IncrementCounterResponse struct {
@@ -260,6 +479,44 @@ type IncrementCounterResponse struct {
 	Incremented bool   `json:"incremented"`
 }
 
+type GetCounterRequest struct {
+	Group string `json:"group"`
+	Name  string `json:"name"`
+}
+
+type GetCounterResponse struct {
+	Value uint64 `json:"value"`
+}
+
+// DecrementCounterRequest decrements by Count unless the value would drop below Floor
+type DecrementCounterRequest struct {
+	Group string `json:"group"`
+	Name  string `json:"name"`
+	Count uint64 `json:"count"`
+	Floor uint64 `json:"floor"`
+	TTL   int64  `json:"TTL"`
+}
+
+type DecrementCounterResponse struct {
+	Value       uint64 `json:"value"`
+	Decremented bool   `json:"decremented"`
+}
+
+type ResetCounterRequest struct {
+	Group string `json:"group"`
+	Name  string `json:"name"`
+	TTL   int64  `json:"TTL"`
+}
+
+type GetCountersRequest struct {
+	Group string   `json:"group"`
+	Names []string `json:"names"`
+}
+
+type GetCountersResponse struct {
+	Values map[string]uint64 `json:"values"`
+}
+
 // ServiceClient is a reusable client for calling the service API
 type ServiceClient struct {
 	httpClient *http.Client
@@ -296,6 +553,73 @@ func (sc *ServiceClient) ExecService(sessionId string, req ExecServiceRequest) (
 	return res, nil
 }
 
+// ExecServiceAsync starts a service task and returns its task id without waiting for the output
+func (sc *ServiceClient) ExecServiceAsync(sessionId string, req ExecServiceRequest) (ExecAsyncResponse, error) {
+	var res ExecAsyncResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/service/exec-async", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ExecAppAsync(sessionId string, req ExecAppRequest) (ExecAsyncResponse, error) {
+	var res ExecAsyncResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/app/exec-async", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) AwaitTasks(sessionId string, req AwaitTasksRequest) (AwaitTasksResponse, error) {
+	var res AwaitTasksResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/task/await", req, &res)
+	if err != nil {
+		return AwaitTasksResponse{}, err
+	}
+
+	if res.IsAsync {
+		panic(ErrTaskStopped)
+	}
+
+	return res, nil
+}
+
+func (sc *ServiceClient) GetVersion(sessionId string, req GetVersionRequest) (GetVersionResponse, error) {
+	var res GetVersionResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/version/get", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ListDeadLetters(sessionId string, req ListDeadLettersRequest) (ListDeadLettersResponse, error) {
+	var res ListDeadLettersResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/deadletter/list", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) GetDeadLetter(sessionId string, req DeadLetterRequest) (GetDeadLetterResponse, error) {
+	var res GetDeadLetterResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/deadletter/get", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ReplayDeadLetter(sessionId string, req DeadLetterRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/deadletter/replay", req)
+}
+
+func (sc *ServiceClient) DeleteDeadLetter(sessionId string, req DeadLetterRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/deadletter/delete", req)
+}
+
+func (sc *ServiceClient) ContinueAsNew(sessionId string, req ContinueAsNewRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/task/continue-as-new", req)
+}
+
+func (sc *ServiceClient) CancelTask(sessionId string, req CancelTaskRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/task/cancel", req)
+}
+
+func (sc *ServiceClient) QueryWorkflow(sessionId string, req QueryWorkflowRequest) (QueryWorkflowResponse, error) {
+	var res QueryWorkflowResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/service/query", req, &res)
+	return res, err
+}
+
 func (sc *ServiceClient) ExecApp(sessionId string, req ExecAppRequest) (ExecAppResponse, error) {
 	var res ExecAppResponse
 	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/app/exec", req, &res)
@@ -419,6 +743,20 @@ func (sc *ServiceClient) RenameFile(sessionId string, req RenameFileRequest) err
 	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/rename", req)
 }
 
+func (sc *ServiceClient) PromoteFile(sessionId string, req PromoteFileRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/promote", req)
+}
+
+func (sc *ServiceClient) ListTempFiles(sessionId string, req ListTempFilesRequest) (ListTempFilesResponse, error) {
+	var res ListTempFilesResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/list-temp", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) PurgeTempFiles(sessionId string, req PurgeTempFilesRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/purge-temp", req)
+}
+
 func (sc *ServiceClient) ListFile(sessionId string, req ListFilePageRequest) (ListFilePageResponse, error) {
 	var res ListFilePageResponse
 	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/list", req, &res)
@@ -429,6 +767,22 @@ func (sc *ServiceClient) CreateFolder(sessionId string, req CreateFolderRequest)
 	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/create-folder", req)
 }
 
+func (sc *ServiceClient) ListFileVersions(sessionId string, req ListFileVersionsRequest) (ListFileVersionsResponse, error) {
+	var res ListFileVersionsResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/versions", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) GetFileVersion(sessionId string, req GetFileVersionRequest) (GetFileResponse, error) {
+	var res GetFileResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/get-version", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) RestoreFileVersion(sessionId string, req RestoreFileVersionRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/file/restore-version", req)
+}
+
 func (sc *ServiceClient) EmitSignal(sessionId string, req SignalEmitRequest) error {
 	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/signal/emit", req)
 }
@@ -439,16 +793,85 @@ func (sc *ServiceClient) WaitForSignal(sessionId string, req SignalWaitRequest)
 	return res, err
 }
 
+func (sc *ServiceClient) ReceiveSignal(sessionId string, req SignalReceiveRequest) (SignalReceiveResponse, error) {
+	var res SignalReceiveResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/signal/channel/receive", req, &res)
+	if err != nil {
+		return SignalReceiveResponse{}, err
+	}
+
+	if res.IsAsync {
+		panic(ErrTaskStopped)
+	}
+
+	return res, nil
+}
+
+func (sc *ServiceClient) SignalChannelLen(sessionId string, req SignalChannelLenRequest) (SignalChannelLenResponse, error) {
+	var res SignalChannelLenResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/signal/channel/len", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) StartTimer(sessionId string, req StartTimerRequest) (StartTimerResponse, error) {
+	var res StartTimerResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/timer/start", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) AwaitTimer(sessionId string, req AwaitTimerRequest) (AwaitTimerResponse, error) {
+	var res AwaitTimerResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/timer/await", req, &res)
+	if err != nil {
+		return AwaitTimerResponse{}, err
+	}
+
+	if res.IsAsync {
+		panic(ErrTaskStopped)
+	}
+
+	return res, nil
+}
+
 func (sc *ServiceClient) EmitRealtimeEvent(sessionId string, req RealtimeEventEmitRequest) error {
 	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/realtime/event/emit", req)
 }
 
-func (sc *ServiceClient) AcquireLock(sessionId string, req AcquireLockRequest) error {
-	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/lock/acquire", req)
+func (sc *ServiceClient) AcquireLock(sessionId string, req AcquireLockRequest) (AcquireLockResponse, error) {
+	var res AcquireLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/lock/acquire", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) RenewLock(sessionId string, req RenewLockRequest) (RenewLockResponse, error) {
+	var res RenewLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/lock/renew", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ReleaseLock(sessionId string, req ReleaseLockRequest) (ReleaseLockResponse, error) {
+	var res ReleaseLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/lock/release", req, &res)
+	return res, err
+}
+
+// AcquireAppLock takes a lock owned by the app instance rather than a task, e.g. for leader election
+func (sc *ServiceClient) AcquireAppLock(req AcquireLockRequest) (AcquireLockResponse, error) {
+	var res AcquireLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, "", "v1/system/lock/acquire", req, &res)
+	return res, err
 }
 
-func (sc *ServiceClient) ReleaseLock(sessionId string, req ReleaseLockRequest) error {
-	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/context/lock/release", req)
+func (sc *ServiceClient) RenewAppLock(req RenewLockRequest) (RenewLockResponse, error) {
+	var res RenewLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, "", "v1/system/lock/renew", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ReleaseAppLock(req ReleaseLockRequest) (ReleaseLockResponse, error) {
+	var res ReleaseLockResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, "", "v1/system/lock/release", req, &res)
+	return res, err
 }
 
 func (sc *ServiceClient) IncrementCounter(sessionId string, req IncrementCounterRequest) (IncrementCounterResponse, error) {
@@ -457,6 +880,28 @@ func (sc *ServiceClient) IncrementCounter(sessionId string, req IncrementCounter
 	return res, err
 }
 
+func (sc *ServiceClient) GetCounter(sessionId string, req GetCounterRequest) (GetCounterResponse, error) {
+	var res GetCounterResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/elevated/context/counter/get", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) DecrementCounter(sessionId string, req DecrementCounterRequest) (DecrementCounterResponse, error) {
+	var res DecrementCounterResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/elevated/context/counter/decrement", req, &res)
+	return res, err
+}
+
+func (sc *ServiceClient) ResetCounter(sessionId string, req ResetCounterRequest) error {
+	return executeApiWithoutResponse(sc.httpClient, sc.baseURL, sessionId, "v1/elevated/context/counter/reset", req)
+}
+
+func (sc *ServiceClient) GetCounters(sessionId string, req GetCountersRequest) (GetCountersResponse, error) {
+	var res GetCountersResponse
+	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/elevated/context/counter/get-many", req, &res)
+	return res, err
+}
+
 func (sc *ServiceClient) GetMeta(sessionId string, req GetMetaDataRequest) (map[string]interface{}, error) {
 	var res map[string]interface{}
 	err := executeApiWithResponse(sc.httpClient, sc.baseURL, sessionId, "v1/elevated/context/meta/get", req, &res)
@@ -485,12 +930,16 @@ func executeApiWithoutResponse(httpClient *http.Client, baseUrl string, sessionI
 
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return err
+		return sidecarUnavailableError{err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http error, status: %v", resp.Status)
+		err = fmt.Errorf("http error, status: %v", resp.Status)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return sidecarUnavailableError{err: err}
+		}
+		return err
 	}
 
 	return nil
@@ -514,7 +963,7 @@ func executeApiWithResponse[T any](httpClient *http.Client, baseUrl string, sess
 
 	resp, err := httpClient.Do(httpReq)
 	if err != nil {
-		return err
+		return sidecarUnavailableError{err: err}
 	}
 	defer resp.Body.Close()
 
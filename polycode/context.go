@@ -2,6 +2,10 @@ package polycode
 
 import (
 	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand"
 	"time"
 )
 
@@ -18,6 +22,8 @@ type BaseContext interface {
 	ParamStore() ParamStore
 	UnsafeDb() *UnsafeDataStoreBuilder
 	FileStore() FileStore
+	Counter(group string, name string, ttl int64) Counter
+	CounterGroup(group string) CounterGroup
 }
 
 type ServiceContext interface {
@@ -36,9 +42,29 @@ type WorkflowContext interface {
 	Controller(controller string) RemoteController
 	ControllerEx(envId string, controller string) RemoteController
 	Memo(getter func() (any, error)) Response
+	MemoKey(key string, getter func() (any, error)) Response
+	Now() (time.Time, error)
+	NewUUID() (string, error)
+	Random() (*rand.Rand, error)
 	Signal(signalName string) Signal
+	SignalChannel(signalName string) SignalChannel
 	ClientChannel(channelName string) ClientChannel
 	Lock(key string) Lock
+	WithLock(key string, ttl time.Duration, fn func() error) error
+	RWLock(key string) RWLock
+	Semaphore(key string, permits uint32) (Semaphore, error)
+	AwaitAll(futures ...*Future) []Response
+	AwaitAny(futures ...*Future) (int, Response)
+	NewTimer(d time.Duration) (Timer, error)
+	Sleep(d time.Duration) error
+	SleepUntil(t time.Time) error
+	Select(cases ...SelectCase) (int, Response)
+	SetQueryHandler(name string, handler QueryHandler)
+	NewCancellationScope() *CancellationScope
+	ContinueAsNew(input any) error
+	GetVersion(changeId string, minVersion int, maxVersion int) (int, error)
+	Patched(changeId string) (bool, error)
+	DeadLetters(destination string) DeadLetterQueue
 }
 
 type ApiContext interface {
@@ -48,7 +74,6 @@ type ApiContext interface {
 type RawContext interface {
 	BaseContext
 	GetMeta(group string, typeName string, key string) (map[string]interface{}, error)
-	Counter(group string, name string, ttl int64) Counter
 }
 
 type ContextImpl struct {
@@ -61,6 +86,22 @@ type ContextImpl struct {
 	logger        Logger
 	meta          ContextMeta
 	authCtx       AuthContext
+	hooks         *exitHooks
+	queryKey      string
+	permitSeq     *permitSequence
+	isWorkflow    bool
+}
+
+// workflowFrom returns ctx as a WorkflowContext when it belongs to a running workflow or api task
+func workflowFrom(ctx context.Context) (WorkflowContext, bool) {
+	switch c := ctx.(type) {
+	case *ContextImpl:
+		return c, c.isWorkflow
+	case ContextImpl:
+		return c, c.isWorkflow
+	default:
+		return nil, false
+	}
 }
 
 func (s ContextImpl) Meta() ContextMeta {
@@ -79,6 +120,7 @@ func (s ContextImpl) Deadline() (deadline time.Time, ok bool) {
 	return s.ctx.Deadline()
 }
 
+// Done is closed when the task is cancelled, workflows can select on it to run cleanup logic
 func (s ContextImpl) Done() <-chan struct{} {
 	return s.ctx.Done()
 }
@@ -160,6 +202,46 @@ func (s ContextImpl) Memo(getter func() (any, error)) Response {
 	return m.Get()
 }
 
+// MemoKey is Memo with a stable key, replaying a different key at the same position fails with ErrNonDeterministic
+func (s ContextImpl) MemoKey(key string, getter func() (any, error)) Response {
+	m := Memo{ctx: s.ctx, sessionId: s.sessionId, key: key, getter: getter, serviceClient: s.serviceClient}
+	return m.Get()
+}
+
+// Now returns the current time, memoized so that replays see the same value
+func (s ContextImpl) Now() (time.Time, error) {
+	return MemoT(s, "@now", func() (time.Time, error) {
+		return time.Now(), nil
+	})
+}
+
+// NewUUID returns a random v4 uuid, memoized so that replays see the same value
+func (s ContextImpl) NewUUID() (string, error) {
+	return MemoT(s, "@uuid", func() (string, error) {
+		var b [16]byte
+		if _, err := crand.Read(b[:]); err != nil {
+			return "", err
+		}
+
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	})
+}
+
+// Random returns a generator seeded with a memoized seed, so replays draw the same sequence
+func (s ContextImpl) Random() (*rand.Rand, error) {
+	// seed is kept within uint32 so it survives the json round trip through the sidecar
+	seed, err := MemoT(s, "@random", func() (uint32, error) {
+		return rand.Uint32(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rand.New(rand.NewSource(int64(seed))), nil
+}
+
 func (s ContextImpl) Logger() Logger {
 	return s.logger
 }
@@ -172,6 +254,15 @@ func (s ContextImpl) Signal(signalName string) Signal {
 	}
 }
 
+func (s ContextImpl) SignalChannel(signalName string) SignalChannel {
+	return SignalChannel{
+		ctx:           s,
+		name:          signalName,
+		sessionId:     s.sessionId,
+		serviceClient: s.serviceClient,
+	}
+}
+
 func (s ContextImpl) ClientChannel(channelName string) ClientChannel {
 	return ClientChannel{
 		name:          channelName,
@@ -181,10 +272,156 @@ func (s ContextImpl) ClientChannel(channelName string) ClientChannel {
 }
 
 func (s ContextImpl) Lock(key string) Lock {
-	return Lock{
-		client:    s.serviceClient,
-		sessionId: s.sessionId,
-		key:       key,
+	return newTaskLock(s, key, LockExclusive, 0)
+}
+
+func (s ContextImpl) RWLock(key string) RWLock {
+	return RWLock{
+		ctx: s,
+		key: key,
+	}
+}
+
+func (s ContextImpl) Semaphore(key string, permits uint32) (Semaphore, error) {
+	if permits == 0 {
+		return Semaphore{}, ErrBadRequest.Wrap(fmt.Errorf("semaphore %s needs at least one permit", key))
+	}
+
+	return Semaphore{
+		ctx:     s,
+		key:     key,
+		permits: permits,
+	}, nil
+}
+
+// WithLock runs fn while holding the lock for key, waiting for the lock if it is held elsewhere.
+// The lock is renewed in the background while fn runs and is released when fn returns or panics.
+// When fn suspends the workflow the lock is kept and the replay takes it back as the same owner,
+// but renewal stops with the task, so the hold only survives suspensions shorter than ttl.
+func (s ContextImpl) WithLock(key string, ttl time.Duration, fn func() error) (err error) {
+	l := s.Lock(key)
+	if err = l.AcquireWait(s.ctx, ttl); err != nil {
+		return err
+	}
+
+	l.AutoRenew(ttl)
+	defer func() {
+		if r := recover(); r != nil {
+			if recovered, ok := r.(error); !ok || !errors.Is(recovered, ErrTaskStopped) {
+				_ = l.Release()
+			}
+			panic(r)
+		}
+
+		if releaseErr := l.Release(); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	return fn()
+}
+
+func (s ContextImpl) AwaitAll(futures ...*Future) []Response {
+	awaitFutures(true, futures...)
+
+	responses := make([]Response, len(futures))
+	for i, f := range futures {
+		responses[i] = f.result()
+	}
+	return responses
+}
+
+// AwaitAny returns the index and response of the first completed future, or -1 if none completed
+func (s ContextImpl) AwaitAny(futures ...*Future) (int, Response) {
+	awaitFutures(false, futures...)
+
+	for i, f := range futures {
+		if f.done.Load() {
+			return i, f.response
+		}
+	}
+
+	return -1, Response{
+		output:  nil,
+		isError: true,
+		error:   ErrTaskExecError.Wrap(errors.New("no task completed")),
+	}
+}
+
+func (s ContextImpl) NewTimer(d time.Duration) (Timer, error) {
+	return startTimer(s.sessionId, s.serviceClient, time.Now().Add(d))
+}
+
+// Sleep durably suspends the workflow for d
+func (s ContextImpl) Sleep(d time.Duration) error {
+	return s.SleepUntil(time.Now().Add(d))
+}
+
+func (s ContextImpl) SleepUntil(t time.Time) error {
+	timer, err := startTimer(s.sessionId, s.serviceClient, t)
+	if err != nil {
+		return err
+	}
+
+	return timer.Await()
+}
+
+// Select waits for the first of several signals and timers and returns the index of the case that fired
+func (s ContextImpl) Select(cases ...SelectCase) (int, Response) {
+	return selectCases(s.sessionId, s.serviceClient, cases)
+}
+
+// SetQueryHandler exposes handler to RemoteService.QueryWorkflow. While the workflow is suspended the
+// handler is registered again by the replay the sidecar runs to answer the query, so it must be set
+// before the workflow first waits and must not have side effects.
+func (s ContextImpl) SetQueryHandler(name string, handler QueryHandler) {
+	workflowQueries.set(s.queryKey, name, handler)
+}
+
+func (s ContextImpl) NewCancellationScope() *CancellationScope {
+	return newCancellationScope(s.ctx)
+}
+
+// ContinueAsNew completes the current task and starts a fresh execution of the same method with input,
+// dropping the accumulated memo and signal history. It does not return unless the sidecar call fails.
+func (s ContextImpl) ContinueAsNew(input any) error {
+	req := ContinueAsNewRequest{
+		Input: input,
+	}
+
+	err := s.serviceClient.ContinueAsNew(s.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: continue as new error: %v\n", err)
+		return wrapTaskError(err)
+	}
+
+	if s.hooks != nil {
+		s.hooks.completed = true
+	}
+	panic(ErrTaskStopped)
+}
+
+// GetVersion returns the durably recorded version of a code change. New executions get maxVersion while
+// executions that passed this point before the change get DefaultVersion, so both keep a consistent path.
+func (s ContextImpl) GetVersion(changeId string, minVersion int, maxVersion int) (int, error) {
+	return getVersion(s.sessionId, s.serviceClient, changeId, minVersion, maxVersion)
+}
+
+// Patched reports whether the execution should take the new code path of changeId
+func (s ContextImpl) Patched(changeId string) (bool, error) {
+	version, err := getVersion(s.sessionId, s.serviceClient, changeId, DefaultVersion, 1)
+	if err != nil {
+		return false, err
+	}
+
+	return version == 1, nil
+}
+
+func (s ContextImpl) DeadLetters(destination string) DeadLetterQueue {
+	return DeadLetterQueue{
+		destination:   destination,
+		sessionId:     s.sessionId,
+		serviceClient: s.serviceClient,
 	}
 }
 
@@ -207,3 +444,11 @@ func (s ContextImpl) Counter(group string, name string, ttl int64) Counter {
 		ttl:       ttl,
 	}
 }
+
+func (s ContextImpl) CounterGroup(group string) CounterGroup {
+	return CounterGroup{
+		client:    s.serviceClient,
+		sessionId: s.sessionId,
+		group:     group,
+	}
+}
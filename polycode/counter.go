@@ -9,7 +9,17 @@ type Counter struct {
 }
 
 func (c *Counter) Get() (uint64, error) {
-	panic("not implemented")
+	req := GetCounterRequest{
+		Group: c.group,
+		Name:  c.name,
+	}
+
+	res, err := c.client.GetCounter(c.sessionId, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Value, nil
 }
 
 func (c *Counter) Increment(count uint64) (uint64, bool, error) {
@@ -34,5 +44,68 @@ func (c *Counter) IncrementWithLimit(count uint64, limit uint64) (uint64, bool,
 }
 
 func (c *Counter) Decrement(count uint64) (uint64, bool, error) {
-	panic("not implemented")
+	return c.DecrementWithFloor(count, 0)
+}
+
+// DecrementWithFloor decrements the counter unless the value would drop below floor
+func (c *Counter) DecrementWithFloor(count uint64, floor uint64) (uint64, bool, error) {
+	req := DecrementCounterRequest{
+		Group: c.group,
+		Name:  c.name,
+		Count: count,
+		Floor: floor,
+		TTL:   c.ttl,
+	}
+
+	res, err := c.client.DecrementCounter(c.sessionId, req)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return res.Value, res.Decremented, nil
+}
+
+func (c *Counter) Reset() error {
+	req := ResetCounterRequest{
+		Group: c.group,
+		Name:  c.name,
+		TTL:   c.ttl,
+	}
+
+	return c.client.ResetCounter(c.sessionId, req)
+}
+
+type CounterGroup struct {
+	client    *ServiceClient
+	sessionId string
+	group     string
+}
+
+func (g CounterGroup) Counter(name string, ttl int64) Counter {
+	return Counter{
+		client:    g.client,
+		sessionId: g.sessionId,
+		group:     g.group,
+		name:      name,
+		ttl:       ttl,
+	}
+}
+
+// GetMany reads several counters of the group in one call, missing counters are reported as zero
+func (g CounterGroup) GetMany(names []string) (map[string]uint64, error) {
+	req := GetCountersRequest{
+		Group: g.group,
+		Names: names,
+	}
+
+	res, err := g.client.GetCounters(g.sessionId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint64, len(names))
+	for _, name := range names {
+		values[name] = res.Values[name]
+	}
+	return values, nil
 }
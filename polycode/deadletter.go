@@ -0,0 +1,104 @@
+package polycode
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+type DeadLetterKind string
+
+const (
+	DeadLetterService DeadLetterKind = "service"
+	DeadLetterApp     DeadLetterKind = "app"
+)
+
+// DeadLetter is a fire-and-forget task that exhausted its retries. Kind tells which of ServiceRequest,
+// sent with RemoteService.Send, or AppRequest, sent with RemoteApp.Send, holds the failed request.
+type DeadLetter struct {
+	Id             string              `json:"id"`
+	Destination    string              `json:"destination"`
+	Kind           DeadLetterKind      `json:"kind"`
+	ServiceRequest *ExecServiceRequest `json:"serviceRequest,omitempty"`
+	AppRequest     *ExecAppRequest     `json:"appRequest,omitempty"`
+	Error          Error               `json:"error"`
+	Stacktrace     Stacktrace          `json:"stacktrace"`
+	Attempts       int                 `json:"attempts"`
+	FailedAt       time.Time           `json:"failedAt"`
+}
+
+var serviceDeadLetters = make(map[string]string)
+
+// RegisterDeadLetter sets the default dead-letter destination for failed Send calls to service,
+// TaskOptions.WithDeadLetter overrides it per call
+func RegisterDeadLetter(service string, destination string) {
+	log.Printf("client: register dead letter %s for service %s\n", destination, service)
+	serviceDeadLetters[service] = destination
+}
+
+type DeadLetterQueue struct {
+	destination   string
+	sessionId     string
+	serviceClient *ServiceClient
+}
+
+func (q DeadLetterQueue) List(limit int32, nextToken *string) ([]DeadLetter, *string, error) {
+	req := ListDeadLettersRequest{
+		Destination:       q.destination,
+		Limit:             limit,
+		ContinuationToken: nextToken,
+	}
+
+	res, err := q.serviceClient.ListDeadLetters(q.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: list dead letters error: %v\n", err)
+		return nil, nil, err
+	}
+
+	return res.DeadLetters, res.NextContinuationToken, nil
+}
+
+// Get returns the dead letter with its stacktrace decompressed
+func (q DeadLetterQueue) Get(id string) (bool, DeadLetter, error) {
+	req := DeadLetterRequest{
+		Destination: q.destination,
+		Id:          id,
+	}
+
+	res, err := q.serviceClient.GetDeadLetter(q.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: get dead letter error: %v\n", err)
+		return false, DeadLetter{}, err
+	}
+
+	if !res.Exist {
+		return false, DeadLetter{}, nil
+	}
+
+	deadLetter := res.DeadLetter
+	if err = deadLetter.Stacktrace.Extract(); err != nil {
+		fmt.Printf("client: extract stacktrace error: %v\n", err)
+		return true, deadLetter, err
+	}
+
+	return true, deadLetter, nil
+}
+
+// Replay resubmits the failed request and removes it from the queue
+func (q DeadLetterQueue) Replay(id string) error {
+	req := DeadLetterRequest{
+		Destination: q.destination,
+		Id:          id,
+	}
+
+	return q.serviceClient.ReplayDeadLetter(q.sessionId, req)
+}
+
+func (q DeadLetterQueue) Delete(id string) error {
+	req := DeadLetterRequest{
+		Destination: q.destination,
+		Id:          id,
+	}
+
+	return q.serviceClient.DeleteDeadLetter(q.sessionId, req)
+}
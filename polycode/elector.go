@@ -0,0 +1,186 @@
+package polycode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const electorLockPrefix = "polycode.elector:"
+
+// Elector campaigns for leadership of name across all instances of the app, so work such as
+// periodic jobs can run on exactly one of them. Leadership is a Lock renewed every third of
+// the ttl, and is released when the elector is stopped or the app shuts down.
+type Elector struct {
+	name      string
+	ttl       time.Duration
+	lock      Lock
+	mu        sync.Mutex
+	leader    bool
+	token     uint64
+	onElected []func()
+	onRevoked []func()
+	changes   chan bool
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func NewElector(name string, ttl time.Duration) *Elector {
+	return &Elector{
+		name:    name,
+		ttl:     ttl,
+		lock:    newAppLock(electorLockPrefix + name),
+		changes: make(chan bool, 1),
+	}
+}
+
+// OnElected registers fn to run when this instance becomes the leader
+func (e *Elector) OnElected(fn func()) *Elector {
+	e.mu.Lock()
+	e.onElected = append(e.onElected, fn)
+	e.mu.Unlock()
+	return e
+}
+
+// OnRevoked registers fn to run when this instance stops being the leader
+func (e *Elector) OnRevoked(fn func()) *Elector {
+	e.mu.Lock()
+	e.onRevoked = append(e.onRevoked, fn)
+	e.mu.Unlock()
+	return e
+}
+
+// Changes delivers the leadership state whenever it changes. Only the latest state is kept,
+// so a slow reader never blocks the elector.
+func (e *Elector) Changes() <-chan bool {
+	return e.changes
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// FencingToken returns the token of the current leadership term, to guard writes made as leader
+func (e *Elector) FencingToken() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.token
+}
+
+// Start begins campaigning in the background, it is a no-op if the elector is already running
+func (e *Elector) Start() *Elector {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		return e
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+
+	removeHook := shutdownHooks.add(e.Stop)
+	go func() {
+		defer close(e.done)
+		defer removeHook()
+		e.campaign(ctx)
+	}()
+	return e
+}
+
+// Stop ends the campaign and releases leadership if held
+func (e *Elector) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel = nil
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+func (e *Elector) campaign(ctx context.Context) {
+	retryInterval := e.ttl / 3
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+
+	var lost <-chan struct{}
+	for {
+		if lost == nil {
+			// the lock is only used by this goroutine, e.mu is not held across the sidecar call
+			err := e.lock.Acquire(e.ttl)
+			if err == nil {
+				lost = e.lock.AutoRenew(e.ttl)
+
+				e.mu.Lock()
+				e.token = e.lock.FencingToken()
+				e.mu.Unlock()
+
+				e.setLeader(true)
+				continue
+			}
+
+			if !IsError(err, ErrLockHeld) {
+				fmt.Printf("client: elector %s failed to acquire leadership: %v\n", e.name, err)
+			}
+
+			timer := time.NewTimer(retryInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := e.lock.Release(); err != nil {
+				fmt.Printf("client: elector %s failed to release leadership: %v\n", e.name, err)
+			}
+			e.setLeader(false)
+			return
+		case <-lost:
+			fmt.Printf("client: elector %s lost leadership\n", e.name)
+			lost = nil
+			e.setLeader(false)
+		}
+	}
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	if e.leader == leader {
+		e.mu.Unlock()
+		return
+	}
+
+	e.leader = leader
+	callbacks := e.onRevoked
+	if leader {
+		callbacks = e.onElected
+	}
+	callbacks = append([]func(){}, callbacks...)
+	e.mu.Unlock()
+
+	// only the campaign goroutine sends, so replacing the buffered value cannot block
+	select {
+	case <-e.changes:
+	default:
+	}
+	e.changes <- leader
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
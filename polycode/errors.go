@@ -3,8 +3,11 @@ package polycode
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strings"
 )
 
@@ -18,6 +21,15 @@ var ErrTaskNotFound = DefineError("polycode.client", 8, "task not found")
 var ErrServiceExecError = DefineError("polycode.client", 9, "service error")
 var ErrApiExecError = DefineError("polycode.client", 10, "api error")
 var CounterExceeded = DefineError("polycode.client", 11, "counter exceeded, count [%d] limit [%d]")
+var ErrUnsupportedArchive = DefineError("polycode.client", 12, "unsupported archive format [%s]")
+var ErrInvalidArchiveEntry = DefineError("polycode.client", 13, "invalid archive entry [%s]")
+var ErrQueryNotFound = DefineError("polycode.client", 14, "query [%s] not registered for task [%s]")
+var ErrSagaCompensated = DefineError("polycode.client", 15, "saga step [%d] failed, completed steps compensated")
+var ErrSagaCompensationFailed = DefineError("polycode.client", 16, "saga step [%d] failed, compensation of step [%d] failed")
+var ErrNonDeterministic = DefineError("polycode.client", 17, "non-deterministic replay, expected memo key [%s] got [%s]")
+var ErrVersionOutOfRange = DefineError("polycode.client", 18, "version [%d] of change [%s] not in supported range [%d, %d]")
+var ErrLockHeld = DefineError("polycode.client", 19, "lock [%s] is held by another owner")
+var ErrLockNotOwned = DefineError("polycode.client", 20, "lock [%s] is not owned by this holder")
 
 type Error struct {
 	Module   string
@@ -149,6 +161,73 @@ func IsError(err error, dst Error) bool {
 	return ret.Module == dst.Module && ret.ErrorNo == dst.ErrorNo
 }
 
+// sidecarUnavailableError marks a request that never got an answer from the sidecar. It is the
+// only failure of a sidecar call that is retried by default.
+type sidecarUnavailableError struct {
+	err error
+}
+
+func (e sidecarUnavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e sidecarUnavailableError) Unwrap() error {
+	return e.err
+}
+
+func (e sidecarUnavailableError) Temporary() bool {
+	return true
+}
+
+// wrapTaskError wraps the failure of a sidecar call in ErrTaskExecError, retryable only when the
+// sidecar could not be reached or itself reported a retryable error
+func wrapTaskError(err error) Error {
+	return ErrTaskExecError.Wrap(err).Retry(IsRetryable(err))
+}
+
+// AsError finds the first polycode error, by value or pointer, in the chain of err
+func AsError(err error) (Error, bool) {
+	var pErr Error
+	if errors.As(err, &pErr) {
+		return pErr, true
+	}
+
+	var pErrPtr *Error
+	if errors.As(err, &pErrPtr) && pErrPtr != nil {
+		return *pErrPtr, true
+	}
+
+	return Error{}, false
+}
+
+// IsRetryable reports whether a failed task may succeed when retried. Polycode errors carry their own
+// CanRetry flag, which is false unless set with Retry, and bad requests are never retried. Other errors
+// are retried only when they are known to be transient, i.e. timeouts and temporary network failures.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if pErr, ok := AsError(err); ok {
+		if IsError(pErr, ErrBadRequest) {
+			return false
+		}
+		return pErr.CanRetry
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
 func IsPolycodeError(err error) bool {
 	_, ok := err.(Error)
 	return ok
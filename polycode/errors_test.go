@@ -0,0 +1,75 @@
+package polycode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var errTestDefined = DefineError("polycode.test", 1, "test error")
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+func TestAsError(t *testing.T) {
+	wrapped := errTestDefined.Wrap(errors.New("cause"))
+	tests := []struct {
+		name   string
+		err    error
+		want   Error
+		wantOk bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain", err: errors.New("plain")},
+		{name: "value", err: wrapped, want: wrapped, wantOk: true},
+		{name: "pointer", err: &wrapped, want: wrapped, wantOk: true},
+		{name: "wrapped value", err: fmt.Errorf("ctx: %w", wrapped), want: wrapped, wantOk: true},
+		{name: "wrapped pointer", err: fmt.Errorf("ctx: %w", &wrapped), want: wrapped, wantOk: true},
+		{name: "nil pointer", err: (*Error)(nil)},
+	}
+
+	for _, tt := range tests {
+		got, ok := AsError(tt.err)
+		if ok != tt.wantOk {
+			t.Errorf("%s: AsError ok = %v, want %v", tt.name, ok, tt.wantOk)
+			continue
+		}
+
+		if ok && (got.Module != tt.want.Module || got.ErrorNo != tt.want.ErrorNo || got.CauseBy != tt.want.CauseBy) {
+			t.Errorf("%s: AsError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "plain", err: errors.New("business rule"), want: false},
+		{name: "defined", err: errTestDefined, want: false},
+		{name: "defined retry", err: errTestDefined.Retry(true), want: true},
+		{name: "defined retry pointer", err: func() error { e := errTestDefined.Retry(true); return &e }(), want: true},
+		{name: "wrapped defined retry", err: fmt.Errorf("ctx: %w", errTestDefined.Retry(true)), want: true},
+		{name: "bad request", err: ErrBadRequest.Retry(true), want: false},
+		{name: "task exec error", err: ErrTaskExecError.Wrap(errors.New("business rule")), want: false},
+		{name: "sidecar unavailable", err: sidecarUnavailableError{err: errors.New("connection refused")}, want: true},
+		{name: "wrapped sidecar unavailable", err: wrapTaskError(sidecarUnavailableError{err: errors.New("connection refused")}), want: true},
+		{name: "wrapped sidecar error", err: wrapTaskError(errors.New("http error, status: 400")), want: false},
+		{name: "deadline", err: fmt.Errorf("call: %w", context.DeadlineExceeded), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "net timeout", err: timeoutError{}, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
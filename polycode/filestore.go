@@ -4,11 +4,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"time"
 )
 
 type FileStore struct {
 	client    *ServiceClient
 	sessionId string
+	exitHooks *exitHooks
 }
 
 func (d FileStore) NewFolder(name string) (Folder, error) {
@@ -103,11 +105,17 @@ func (d FileStore) Save(path string, data []byte) error {
 }
 
 func (d FileStore) SaveTemp(path string, data []byte) error {
+	return d.SaveTempWithTTL(path, data, 0)
+}
+
+// SaveTempWithTTL saves a temp file that expires after expireIn, zero uses the sidecar default
+func (d FileStore) SaveTempWithTTL(path string, data []byte, expireIn time.Duration) error {
 	// Encode the data as base64
 	base64Data := base64.StdEncoding.EncodeToString(data)
 	req := PutFileRequest{
 		Key:      path,
 		TempFile: true,
+		TTL:      tempFileTTL(expireIn),
 		Content:  base64Data,
 	}
 
@@ -137,9 +145,14 @@ func (d FileStore) Upload(path string, filePath string) error {
 }
 
 func (d FileStore) UploadTemp(path string, filePath string) error {
+	return d.UploadTempWithTTL(path, filePath, 0)
+}
+
+func (d FileStore) UploadTempWithTTL(path string, filePath string, expireIn time.Duration) error {
 	req := PutFileRequest{
 		Key:      path,
 		TempFile: true,
+		TTL:      tempFileTTL(expireIn),
 		FilePath: filePath,
 	}
 
@@ -172,9 +185,14 @@ func (d FileStore) GetUploadLink(path string) (string, error) {
 }
 
 func (d FileStore) GetTempUploadLink(path string) (string, error) {
+	return d.GetTempUploadLinkWithTTL(path, 0)
+}
+
+func (d FileStore) GetTempUploadLinkWithTTL(path string, expireIn time.Duration) (string, error) {
 	req := GetUploadLinkRequest{
 		Key:      path,
 		TempFile: true,
+		TTL:      tempFileTTL(expireIn),
 	}
 
 	res, err := d.client.GetFileUploadLink(d.sessionId, req)
@@ -219,6 +237,115 @@ func (d FileStore) Move(oldPath string, newPath string) error {
 	return nil
 }
 
+// Versions lists the prior versions of the file at path, newest first
+func (d FileStore) Versions(path string) ([]FileVersion, error) {
+	req := ListFileVersionsRequest{
+		Key: path,
+	}
+
+	res, err := d.client.ListFileVersions(d.sessionId, req)
+	if err != nil {
+		fmt.Printf("failed to list file versions: %s\n", err.Error())
+		return nil, err
+	}
+
+	return res.Versions, nil
+}
+
+func (d FileStore) GetVersion(path string, versionId string) (bool, []byte, error) {
+	req := GetFileVersionRequest{
+		Key:       path,
+		VersionId: versionId,
+	}
+
+	res, err := d.client.GetFileVersion(d.sessionId, req)
+	if err != nil {
+		fmt.Printf("failed to get file version: %s\n", err.Error())
+		return false, nil, err
+	}
+
+	if res.Content == "" {
+		return false, nil, nil
+	}
+
+	// Decode the base64 data
+	data, err := base64.StdEncoding.DecodeString(res.Content)
+	if err != nil {
+		fmt.Printf("failed to decode base64: %s\n", err.Error())
+		return true, nil, err
+	}
+
+	return true, data, nil
+}
+
+// Restore makes the given version the current content of path. The overwritten content
+// is kept as a new version so a restore can itself be undone.
+func (d FileStore) Restore(path string, versionId string) error {
+	req := RestoreFileVersionRequest{
+		Key:       path,
+		VersionId: versionId,
+	}
+
+	err := d.client.RestoreFileVersion(d.sessionId, req)
+	if err != nil {
+		fmt.Printf("failed to restore file version: %s\n", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Promote turns a temp file into a permanent file at permanentPath
+func (d FileStore) Promote(tempPath string, permanentPath string) error {
+	req := PromoteFileRequest{
+		TempKey: tempPath,
+		Key:     permanentPath,
+	}
+
+	err := d.client.PromoteFile(d.sessionId, req)
+	if err != nil {
+		fmt.Printf("failed to promote file: %s\n", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// TempFiles lists the temp files created by the current session
+func (d FileStore) TempFiles() ([]ListFileResponse, error) {
+	res, err := d.client.ListTempFiles(d.sessionId, ListTempFilesRequest{})
+	if err != nil {
+		fmt.Printf("failed to list temp files: %s\n", err.Error())
+		return nil, err
+	}
+
+	return res.Files, nil
+}
+
+// PurgeTempFiles deletes all temp files created by the current session
+func (d FileStore) PurgeTempFiles() error {
+	err := d.client.PurgeTempFiles(d.sessionId, PurgeTempFilesRequest{})
+	if err != nil {
+		fmt.Printf("failed to purge temp files: %s\n", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// PurgeTempFilesOnExit purges the session temp files once the task completes or fails.
+// Nothing is purged while a workflow is only suspended or has a retry left after a retryable failure.
+func (d FileStore) PurgeTempFilesOnExit() {
+	if d.exitHooks == nil {
+		fmt.Printf("failed to register temp file purge: no task in scope\n")
+		return
+	}
+
+	d.exitHooks.add(func() {
+		_ = d.PurgeTempFiles()
+	})
+}
+
 func (d FileStore) Folder(name string) Folder {
 	return Folder{
 		client:    d.client,
@@ -277,11 +404,16 @@ func (f Folder) Save(name string, data []byte) error {
 }
 
 func (f Folder) SaveTemp(name string, data []byte) error {
+	return f.SaveTempWithTTL(name, data, 0)
+}
+
+func (f Folder) SaveTempWithTTL(name string, data []byte, expireIn time.Duration) error {
 	// Encode the data as base64
 	base64Data := base64.StdEncoding.EncodeToString(data)
 	req := PutFileRequest{
 		Key:      f.name + "/" + name,
 		TempFile: true,
+		TTL:      tempFileTTL(expireIn),
 		Content:  base64Data,
 	}
 
@@ -311,9 +443,14 @@ func (f Folder) Upload(name string, filePath string) error {
 }
 
 func (f Folder) UploadTemp(name string, filePath string) error {
+	return f.UploadTempWithTTL(name, filePath, 0)
+}
+
+func (f Folder) UploadTempWithTTL(name string, filePath string, expireIn time.Duration) error {
 	req := PutFileRequest{
 		Key:      f.name + "/" + name,
 		TempFile: true,
+		TTL:      tempFileTTL(expireIn),
 		FilePath: filePath,
 	}
 
@@ -326,9 +463,17 @@ func (f Folder) UploadTemp(name string, filePath string) error {
 	return nil
 }
 
-func newFileStore(client *ServiceClient, sessionId string) FileStore {
+func tempFileTTL(expireIn time.Duration) int64 {
+	if expireIn <= 0 {
+		return 0
+	}
+	return time.Now().Unix() + int64(expireIn.Seconds())
+}
+
+func newFileStore(client *ServiceClient, sessionId string, hooks *exitHooks) FileStore {
 	return FileStore{
 		client:    client,
 		sessionId: sessionId,
+		exitHooks: hooks,
 	}
 }
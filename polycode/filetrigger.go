@@ -0,0 +1,71 @@
+package polycode
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+type FileEventType string
+
+const (
+	FileCreated FileEventType = "created"
+	FileDeleted FileEventType = "deleted"
+)
+
+// FileTrigger invokes Service.Method whenever a file matching Prefix sees one of Events
+type FileTrigger struct {
+	Service string          `json:"service"`
+	Method  string          `json:"method"`
+	Prefix  string          `json:"prefix"`
+	Events  []FileEventType `json:"events"`
+}
+
+// FileEvent is the input passed to a method invoked by a FileTrigger
+type FileEvent struct {
+	Type      FileEventType `json:"type"`
+	Key       string        `json:"key"`
+	Size      int64         `json:"size"`
+	Uploader  string        `json:"uploader"`
+	EventTime time.Time     `json:"eventTime"`
+}
+
+var fileTriggers = make([]FileTrigger, 0)
+
+// RegisterFileTrigger makes the sidecar call service.method with a FileEvent input when a file
+// under prefix is created or deleted. Defaults to created events when none are given.
+func RegisterFileTrigger(service string, method string, prefix string, events ...FileEventType) {
+	log.Printf("client: register file trigger %s.%s on %s\n", service, method, prefix)
+
+	if len(events) == 0 {
+		events = []FileEventType{FileCreated}
+	}
+
+	fileTriggers = append(fileTriggers, FileTrigger{
+		Service: service,
+		Method:  method,
+		Prefix:  prefix,
+		Events:  events,
+	})
+}
+
+// validate checks the event types and that the target method is defined by the registered service
+func (t FileTrigger) validate() error {
+	for _, event := range t.Events {
+		if event != FileCreated && event != FileDeleted {
+			return fmt.Errorf("client: unknown file event type %s for %s.%s", event, t.Service, t.Method)
+		}
+	}
+
+	return checkServiceMethod(t.Service, t.Method)
+}
+
+func loadFileTriggers() ([]FileTrigger, error) {
+	for _, trigger := range fileTriggers {
+		if err := trigger.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return fileTriggers, nil
+}
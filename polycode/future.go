@@ -0,0 +1,131 @@
+package polycode
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Future is the handle of a task started with RequestReplyAsync or CallAsync
+type Future struct {
+	taskId        string
+	sessionId     string
+	serviceClient *ServiceClient
+	// done is read by cancellation scopes on other goroutines, response only by the workflow goroutine
+	done     atomic.Bool
+	response Response
+}
+
+func newFuture(sessionId string, serviceClient *ServiceClient, res ExecAsyncResponse) *Future {
+	f := &Future{
+		taskId:        res.TaskId,
+		sessionId:     sessionId,
+		serviceClient: serviceClient,
+	}
+
+	if res.IsCompleted {
+		f.complete(res.Output, res.IsError, res.Error)
+	}
+	return f
+}
+
+func failedFuture(sessionId string, serviceClient *ServiceClient, err Error) *Future {
+	f := &Future{
+		sessionId:     sessionId,
+		serviceClient: serviceClient,
+	}
+
+	f.complete(nil, true, err)
+	return f
+}
+
+func (f *Future) complete(output any, isError bool, err Error) {
+	f.response = Response{
+		output:  output,
+		isError: isError,
+		error:   err,
+	}
+	f.done.Store(true)
+}
+
+func (f *Future) TaskId() string {
+	return f.taskId
+}
+
+func (f *Future) IsDone() bool {
+	return f.done.Load()
+}
+
+// Get waits for the task to complete, suspending the workflow if it is still running
+func (f *Future) Get() Response {
+	awaitFutures(true, f)
+	return f.result()
+}
+
+// result returns the task response, or an error response if the task has not completed
+func (f *Future) result() Response {
+	if !f.done.Load() {
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   ErrTaskExecError.Wrap(fmt.Errorf("task %s not completed", f.taskId)),
+		}
+	}
+	return f.response
+}
+
+// awaitFutures waits until all (or any) pending futures complete. Futures must share the same session.
+// The workflow is suspended once through ErrTaskStopped while the sidecar waits for the children.
+func awaitFutures(waitAll bool, futures ...*Future) {
+	pending := make(map[string]*Future)
+	var taskIds []string
+	var first *Future
+	for _, f := range futures {
+		if f.done.Load() {
+			if !waitAll {
+				return
+			}
+			continue
+		}
+
+		if first == nil {
+			first = f
+		}
+		pending[f.taskId] = f
+		taskIds = append(taskIds, f.taskId)
+	}
+
+	if first == nil {
+		return
+	}
+
+	req := AwaitTasksRequest{
+		TaskIds: taskIds,
+		WaitAll: waitAll,
+	}
+
+	res, err := first.serviceClient.AwaitTasks(first.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: await tasks error: %v\n", err)
+		for _, f := range pending {
+			f.complete(nil, true, wrapTaskError(err))
+		}
+		return
+	}
+
+	for _, result := range res.Results {
+		f := pending[result.TaskId]
+		if f == nil || !result.IsCompleted {
+			continue
+		}
+
+		f.complete(result.Output, result.IsError, result.Error)
+		delete(pending, result.TaskId)
+	}
+
+	if waitAll {
+		// the sidecar answered without suspending, so a task missing from the results will not complete
+		for taskId, f := range pending {
+			f.complete(nil, true, ErrTaskExecError.Wrap(fmt.Errorf("task %s not completed", taskId)))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package polycode
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// exitHooks holds cleanup callbacks that run once a task completes or fails for good.
+// They are skipped when the task is only suspended with ErrTaskStopped or fails with
+// a retryable error while the sidecar has retries left, unless completed is set because the task ended through that
+// path, e.g. continue-as-new. Stop callbacks run whenever the task leaves this
+// process, including suspension, to end background work started by the task.
+type exitHooks struct {
+	hooks     []func()
+	stops     []func()
+	completed bool
+}
+
+func (h *exitHooks) add(hook func()) {
+	h.hooks = append(h.hooks, hook)
+}
+
+func (h *exitHooks) addStop(stop func()) {
+	h.stops = append(h.stops, stop)
+}
+
+func (h *exitHooks) runStops() {
+	for _, stop := range h.stops {
+		stop()
+	}
+	h.stops = nil
+}
+
+// run calls the hooks in reverse registration order, a panicking hook does not stop the rest
+func (h *exitHooks) run(logger Logger) {
+	for i := len(h.hooks) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Printf("stack trace %s\n", string(debug.Stack()))
+					logger.Error().Msg(fmt.Sprintf("exit hook failed: %v", r))
+				}
+			}()
+			h.hooks[i]()
+		}()
+	}
+	h.hooks = nil
+}
@@ -1,28 +1,307 @@
 package polycode
 
-import "time"
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
 
+const (
+	lockWaitMinBackoff = 50 * time.Millisecond
+	lockWaitMaxBackoff = 5 * time.Second
+)
+
+type LockMode string
+
+// LockMode selects how the sidecar shares a key, holders of different modes never share a key
+const (
+	LockExclusive LockMode = "exclusive"
+	// LockRead is held together with other readers and excludes LockWrite holders of the same key
+	LockRead LockMode = "read"
+	// LockWrite excludes all other LockRead and LockWrite holders of the same key
+	LockWrite LockMode = "write"
+	// LockSemaphore is held together with other holders up to the permit count
+	LockSemaphore LockMode = "semaphore"
+)
+
+// Lock is a distributed mutex held through the sidecar. Only the owner that acquired the lock can renew
+// or release it. Locks taken from a task context are owned by the task, so a replayed workflow keeps
+// the hold of its earlier execution, and their background renewal stops when the task leaves the process.
 type Lock struct {
-	client    *ServiceClient
-	sessionId string
-	key       string
+	client       *ServiceClient
+	sessionId    string
+	key          string
+	owner        string
+	mode         LockMode
+	permits      uint32
+	fencingToken uint64
+	stopRenew    context.CancelFunc
+	hooks        *exitHooks
+	// appScoped locks belong to the app instance and use the system lock endpoints, which need no session
+	appScoped bool
 }
 
-func (l *Lock) Acquire(expireIn time.Duration) error {
-	ttl := time.Now().Unix() + int64(expireIn.Seconds())
+func newLock(client *ServiceClient, sessionId string, key string, mode LockMode, permits uint32) Lock {
+	return Lock{
+		client:    client,
+		sessionId: sessionId,
+		key:       key,
+		owner:     newLockOwner(),
+		mode:      mode,
+		permits:   permits,
+	}
+}
 
+// newTaskLock returns a lock owned by the task of ctx, the owner is derived from the task id so that
+// replays of the same task get the same owner
+func newTaskLock(ctx ContextImpl, key string, mode LockMode, permits uint32) Lock {
+	l := newLock(ctx.serviceClient, ctx.sessionId, key, mode, permits)
+	if ctx.meta.TaskId != "" {
+		l.owner = fmt.Sprintf("%s:%s:%s", ctx.meta.TaskId, mode, key)
+	}
+	l.hooks = ctx.hooks
+	return l
+}
+
+// newAppLock returns a lock owned by this app instance, not tied to any task session
+func newAppLock(key string) Lock {
+	l := newLock(serviceClient, "", key, LockExclusive, 0)
+	l.appScoped = true
+	return l
+}
+
+func newLockOwner() string {
+	var b [16]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("client: failed to generate lock owner: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func lockExpiry(ttl time.Duration) int64 {
+	return time.Now().Unix() + int64(ttl.Seconds())
+}
+
+// Acquire takes the lock for expireIn, it returns ErrLockHeld without waiting if another owner holds it
+func (l *Lock) Acquire(expireIn time.Duration) error {
 	req := AcquireLockRequest{
-		Key: l.key,
-		TTL: ttl,
+		Key:     l.key,
+		Owner:   l.owner,
+		Mode:    l.mode,
+		Permits: l.permits,
+		TTL:     lockExpiry(expireIn),
+	}
+
+	var res AcquireLockResponse
+	var err error
+	if l.appScoped {
+		res, err = l.client.AcquireAppLock(req)
+	} else {
+		res, err = l.client.AcquireLock(l.sessionId, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !res.Acquired {
+		return ErrLockHeld.With(l.key)
+	}
+
+	l.fencingToken = res.FencingToken
+	return nil
+}
+
+// AcquireWait retries Acquire with exponential backoff until the lock is taken or ctx is done
+func (l *Lock) AcquireWait(ctx context.Context, ttl time.Duration) error {
+	backoff := lockWaitMinBackoff
+	for {
+		err := l.Acquire(ttl)
+		if err == nil || !IsError(err, ErrLockHeld) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, lockWaitMaxBackoff)
+	}
+}
+
+// Renew extends the lock to expire ttl from now, it returns ErrLockNotOwned if the lock was lost
+func (l *Lock) Renew(ttl time.Duration) error {
+	req := RenewLockRequest{
+		Key:          l.key,
+		Owner:        l.owner,
+		FencingToken: l.fencingToken,
+		TTL:          lockExpiry(ttl),
+	}
+
+	var res RenewLockResponse
+	var err error
+	if l.appScoped {
+		res, err = l.client.RenewAppLock(req)
+	} else {
+		res, err = l.client.RenewLock(l.sessionId, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !res.Renewed {
+		return ErrLockNotOwned.With(l.key)
+	}
+	return nil
+}
+
+// AutoRenew renews the lock in the background every third of ttl until Release is called or the task
+// holding it completes or suspends. The returned channel is closed if the lock is lost, either taken
+// over or not renewed within ttl.
+func (l *Lock) AutoRenew(ttl time.Duration) <-chan struct{} {
+	l.stopAutoRenew()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.stopRenew = cancel
+	if l.hooks != nil {
+		l.hooks.addStop(cancel)
 	}
+	lost := make(chan struct{})
+
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
 
-	return l.client.AcquireLock(l.sessionId, req)
+		lastRenewed := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			err := l.Renew(ttl)
+			if err == nil {
+				lastRenewed = time.Now()
+				continue
+			}
+
+			fmt.Printf("client: lock %s renewal failed: %v\n", l.key, err)
+			if IsError(err, ErrLockNotOwned) || time.Since(lastRenewed) >= ttl {
+				close(lost)
+				return
+			}
+		}
+	}()
+
+	return lost
 }
 
+func (l *Lock) stopAutoRenew() {
+	if l.stopRenew != nil {
+		l.stopRenew()
+		l.stopRenew = nil
+	}
+}
+
+// FencingToken returns the token issued by the last successful Acquire. Tokens increase every time
+// the lock changes hands, so downstream systems can reject writes carrying an older token.
+func (l *Lock) FencingToken() uint64 {
+	return l.fencingToken
+}
+
+// Release stops background renewal and frees the lock, it returns ErrLockNotOwned if the lock
+// is no longer held by this owner
 func (l *Lock) Release() error {
+	l.stopAutoRenew()
+
 	req := ReleaseLockRequest{
-		Key: l.key,
+		Key:          l.key,
+		Owner:        l.owner,
+		FencingToken: l.fencingToken,
+	}
+
+	var res ReleaseLockResponse
+	var err error
+	if l.appScoped {
+		res, err = l.client.ReleaseAppLock(req)
+	} else {
+		res, err = l.client.ReleaseLock(l.sessionId, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !res.Released {
+		return ErrLockNotOwned.With(l.key)
 	}
+	return nil
+}
+
+// RWLock allows any number of readers or a single writer. Each call to ReadLock or WriteLock returns
+// a new holder that is acquired, renewed and released like a Lock.
+type RWLock struct {
+	ctx ContextImpl
+	key string
+}
+
+func (l RWLock) ReadLock() Lock {
+	return newTaskLock(l.ctx, l.key, LockRead, 0)
+}
+
+func (l RWLock) WriteLock() Lock {
+	return newTaskLock(l.ctx, l.key, LockWrite, 0)
+}
+
+// Semaphore caps the number of concurrent holders of key to permits, e.g. to limit the calls
+// in flight to a downstream system.
+type Semaphore struct {
+	ctx     ContextImpl
+	key     string
+	permits uint32
+}
+
+// Permit returns a new holder of one permit, Acquire returns ErrLockHeld when all permits are taken
+func (s Semaphore) Permit() Lock {
+	l := newTaskLock(s.ctx, s.key, LockSemaphore, s.permits)
+	if s.ctx.meta.TaskId != "" && s.ctx.permitSeq != nil {
+		l.owner = fmt.Sprintf("%s:%d", l.owner, s.ctx.permitSeq.next(s.key))
+	}
+	return l
+}
+
+// permitSequence numbers the permits a task takes from each semaphore so that every permit has
+// its own owner. A workflow takes its permits in the same order on every execution, so a replay
+// numbers them, and derives their owners, the same way.
+type permitSequence struct {
+	mu   sync.Mutex
+	seqs map[string]uint64
+}
+
+func newPermitSequence() *permitSequence {
+	return &permitSequence{seqs: make(map[string]uint64)}
+}
+
+func (p *permitSequence) next(key string) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seq := p.seqs[key]
+	p.seqs[key] = seq + 1
+	return seq
+}
 
-	return l.client.ReleaseLock(l.sessionId, req)
+func (s Semaphore) Permits() uint32 {
+	return s.permits
 }
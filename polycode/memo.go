@@ -0,0 +1,16 @@
+package polycode
+
+// MemoT runs fn once and records its result under key, later executions and replays return the
+// recorded value. Replaying a different key at the same position fails with ErrNonDeterministic.
+func MemoT[T any](ctx WorkflowContext, key string, fn func() (T, error)) (T, error) {
+	var ret T
+	res := ctx.MemoKey(key, func() (any, error) {
+		return fn()
+	})
+
+	if err := res.Get(&ret); err != nil {
+		return ret, err
+	}
+
+	return ret, nil
+}
@@ -12,11 +12,19 @@ type BackoffStrategy struct {
 	Multiplier      float64       `json:"multiplier"`
 }
 
+// ErrorRef identifies an error definition by module and number
+type ErrorRef struct {
+	Module  string `json:"module"`
+	ErrorNo int    `json:"errorNo"`
+}
+
 type TaskOptions struct {
 	Timeout         time.Duration   `json:"timeout"`
 	Retries         int             `json:"retries"`
 	RetryOnFail     bool            `json:"retryOnFail"`
 	BackoffStrategy BackoffStrategy `json:"backoffStrategy"`
+	NonRetryable    []ErrorRef      `json:"nonRetryableErrors"`
+	DeadLetter      string          `json:"deadLetter"`
 }
 
 func (t TaskOptions) WithTimeout(timeout time.Duration) TaskOptions {
@@ -24,6 +32,40 @@ func (t TaskOptions) WithTimeout(timeout time.Duration) TaskOptions {
 	return t
 }
 
+// WithRetries retries a failed task up to retries times, zero disables retries
+func (t TaskOptions) WithRetries(retries int) TaskOptions {
+	t.Retries = retries
+	t.RetryOnFail = retries > 0
+	return t
+}
+
+func (t TaskOptions) WithBackoff(initialInterval time.Duration, maxInterval time.Duration, multiplier float64) TaskOptions {
+	t.BackoffStrategy = BackoffStrategy{
+		InitialInterval: initialInterval,
+		MaxInterval:     maxInterval,
+		Multiplier:      multiplier,
+	}
+	return t
+}
+
+// WithDeadLetter stores the request, error and stacktrace in destination when a Send exhausts its retries
+func (t TaskOptions) WithDeadLetter(destination string) TaskOptions {
+	t.DeadLetter = destination
+	return t
+}
+
+// NonRetryableErrors stops retrying when the task fails with one of errs, regardless of CanRetry
+func (t TaskOptions) NonRetryableErrors(errs ...Error) TaskOptions {
+	refs := make([]ErrorRef, 0, len(t.NonRetryable)+len(errs))
+	refs = append(refs, t.NonRetryable...)
+	for _, err := range errs {
+		refs = append(refs, ErrorRef{Module: err.Module, ErrorNo: err.ErrorNo})
+	}
+
+	t.NonRetryable = refs
+	return t
+}
+
 type MethodStartEvent struct {
 	SessionId   string      `json:"sessionId"`
 	Method      string      `json:"method"`
@@ -39,6 +81,17 @@ type ServiceStartEvent struct {
 	Meta        ContextMeta `json:"meta"`
 	AuthContext AuthContext `json:"authContext"`
 	Input       any         `json:"input"`
+	IsCancelled bool        `json:"isCancelled"`
+	// RetriesLeft is the number of attempts the sidecar will still make if this one fails with a retryable error
+	RetriesLeft int `json:"retriesLeft"`
+	// Query is set when the sidecar replays a workflow that is not running in any instance only to answer
+	// a query. The handler is called once the replay suspends or completes, and nothing is recorded.
+	Query *QueryStartEvent `json:"query,omitempty"`
+}
+
+// TaskCancelEvent is sent by the sidecar when a task running in this app is cancelled
+type TaskCancelEvent struct {
+	TaskId string `json:"taskId"`
 }
 
 type ServiceMeta struct {
@@ -52,6 +105,23 @@ type ServiceCompleteEvent struct {
 	Stacktrace Stacktrace  `json:"stacktrace"`
 	Logs       []LogMsg    `json:"logs"`
 	Meta       ServiceMeta `json:"meta"`
+	// Query holds the answer when the task was replayed for ServiceStartEvent.Query
+	Query *QueryCompleteEvent `json:"query,omitempty"`
+}
+
+// QueryStartEvent is sent by the sidecar to read the state of a workflow running in this instance.
+// When the handler is not found because the workflow is suspended, the sidecar replays the workflow
+// with ServiceStartEvent.Query set to answer the query from the replayed state.
+type QueryStartEvent struct {
+	TaskId string `json:"taskId"`
+	Query  string `json:"query"`
+	Input  any    `json:"input"`
+}
+
+type QueryCompleteEvent struct {
+	IsError bool  `json:"isError"`
+	Output  any   `json:"output"`
+	Error   Error `json:"error"`
 }
 
 type ApiStartEvent struct {
@@ -95,8 +165,10 @@ type RouteData struct {
 }
 
 type ServiceDescription struct {
-	Name  string              `json:"name"`
-	Tasks []MethodDescription `json:"tasks"`
+	Name       string              `json:"name"`
+	Tasks      []MethodDescription `json:"tasks"`
+	Schedules  []Schedule          `json:"schedules"`
+	DeadLetter string              `json:"deadLetter"`
 }
 
 type MethodDescription struct {
@@ -112,19 +184,20 @@ type ClientEnv struct {
 }
 
 type ContextMeta struct {
-	OrgId        string            `json:"orgId"`
-	EnvId        string            `json:"envId"`
-	AppName      string            `json:"appName"`
-	AppId        string            `json:"appId"`
-	TenantId     string            `json:"tenantId"`
-	PartitionKey string            `json:"partitionKey"`
-	TaskGroup    string            `json:"taskGroup"`
-	TaskName     string            `json:"taskName"`
-	TaskId       string            `json:"taskId"`
-	ParentId     string            `json:"parentId"`
-	TraceId      string            `json:"traceId"`
-	InputId      string            `json:"inputId"`
-	Caller       CallerContextMeta `json:"caller"`
+	OrgId         string            `json:"orgId"`
+	EnvId         string            `json:"envId"`
+	AppName       string            `json:"appName"`
+	AppId         string            `json:"appId"`
+	TenantId      string            `json:"tenantId"`
+	PartitionKey  string            `json:"partitionKey"`
+	TaskGroup     string            `json:"taskGroup"`
+	TaskName      string            `json:"taskName"`
+	TaskId        string            `json:"taskId"`
+	ParentId      string            `json:"parentId"`
+	TraceId       string            `json:"traceId"`
+	InputId       string            `json:"inputId"`
+	Caller        CallerContextMeta `json:"caller"`
+	ScheduledTime time.Time         `json:"scheduledTime"`
 }
 
 type CallerContextMeta struct {
@@ -0,0 +1,241 @@
+package polycode
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+type RateLimitAlgorithm string
+
+const (
+	FixedWindow   RateLimitAlgorithm = "fixedWindow"
+	SlidingWindow RateLimitAlgorithm = "slidingWindow"
+	TokenBucket   RateLimitAlgorithm = "tokenBucket"
+)
+
+const rateLimitCounterGroup = "polycode.ratelimit"
+
+// RateLimit allows Limit calls per Window. For TokenBucket, Limit is the bucket capacity
+// and Window is the time it takes to refill an empty bucket.
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm `json:"algorithm"`
+	Limit     uint64             `json:"limit"`
+	Window    time.Duration      `json:"window"`
+}
+
+type RateLimitResult struct {
+	Allowed    bool          `json:"allowed"`
+	RetryAfter time.Duration `json:"retryAfter"`
+}
+
+// rateLimitCounter is the part of Counter used by the rate limit algorithms
+type rateLimitCounter interface {
+	Get() (uint64, error)
+	IncrementWithLimit(count uint64, limit uint64) (uint64, bool, error)
+}
+
+// RateLimiter enforces a RateLimit per key using the sidecar counters, so the limit
+// is shared by every instance of the app. When created from a workflow context every
+// decision is memoized, so replays neither consume the limit again nor decide differently.
+type RateLimiter struct {
+	ctx        BaseContext
+	name       string
+	config     RateLimit
+	newCounter func(name string, expireAt time.Time) rateLimitCounter
+}
+
+func NewRateLimiter(ctx BaseContext, name string, config RateLimit) RateLimiter {
+	r := RateLimiter{
+		ctx:    ctx,
+		name:   name,
+		config: config,
+	}
+
+	r.newCounter = func(counterName string, expireAt time.Time) rateLimitCounter {
+		c := ctx.Counter(rateLimitCounterGroup, name+":"+counterName, expireAt.Unix())
+		return &c
+	}
+	return r
+}
+
+func (r RateLimiter) Allow(key string) (bool, error) {
+	res, err := r.Check(key)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Allowed, nil
+}
+
+// Check consumes one call for key and reports when to retry if the limit is reached
+func (r RateLimiter) Check(key string) (RateLimitResult, error) {
+	workflowCtx, isWorkflow := workflowFrom(r.ctx)
+	return r.checkIn(workflowCtx, isWorkflow, key)
+}
+
+func (r RateLimiter) checkIn(workflowCtx WorkflowContext, isWorkflow bool, key string) (RateLimitResult, error) {
+	if isWorkflow {
+		return MemoT(workflowCtx, "@ratelimit:"+r.name+":"+key, func() (RateLimitResult, error) {
+			return r.check(key, time.Now())
+		})
+	}
+	return r.check(key, time.Now())
+}
+
+func (r RateLimiter) check(key string, now time.Time) (RateLimitResult, error) {
+	if r.config.Limit == 0 || r.config.Window <= 0 {
+		return RateLimitResult{}, ErrBadRequest.Wrap(fmt.Errorf("invalid rate limit %d per %s", r.config.Limit, r.config.Window))
+	}
+
+	switch r.config.Algorithm {
+	case FixedWindow:
+		return r.fixedWindow(key, now)
+	case SlidingWindow:
+		return r.slidingWindow(key, now)
+	case TokenBucket:
+		return r.tokenBucket(key, now)
+	default:
+		return RateLimitResult{}, ErrBadRequest.Wrap(fmt.Errorf("unknown rate limit algorithm %s", r.config.Algorithm))
+	}
+}
+
+// Wait blocks until a call for key is allowed. When ctx is a workflow, decisions are memoized and the
+// wait uses a durable timer, so replays neither consume the limit again nor hold the process.
+func (r RateLimiter) Wait(ctx context.Context, key string) error {
+	workflowCtx, isWorkflow := workflowFrom(ctx)
+
+	for {
+		res, err := r.checkIn(workflowCtx, isWorkflow, key)
+		if err != nil {
+			return err
+		}
+
+		if res.Allowed {
+			return nil
+		}
+
+		if isWorkflow {
+			if err = workflowCtx.Sleep(res.RetryAfter); err != nil {
+				return err
+			}
+			continue
+		}
+
+		timer := time.NewTimer(res.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r RateLimiter) counter(name string, expireAt time.Time) rateLimitCounter {
+	return r.newCounter(name, expireAt)
+}
+
+func (r RateLimiter) fixedWindow(key string, now time.Time) (RateLimitResult, error) {
+	window := now.UnixMilli() / r.config.Window.Milliseconds()
+	windowEnd := time.UnixMilli((window + 1) * r.config.Window.Milliseconds())
+
+	c := r.counter(fmt.Sprintf("%s:%d", key, window), windowEnd.Add(r.config.Window))
+	_, incremented, err := c.IncrementWithLimit(1, r.config.Limit)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if incremented {
+		return RateLimitResult{Allowed: true}, nil
+	}
+	return RateLimitResult{RetryAfter: windowEnd.Sub(now)}, nil
+}
+
+// slidingWindow weights the previous window count by how much of it still overlaps the sliding window
+func (r RateLimiter) slidingWindow(key string, now time.Time) (RateLimitResult, error) {
+	windowMs := r.config.Window.Milliseconds()
+	window := now.UnixMilli() / windowMs
+	windowEnd := time.UnixMilli((window + 1) * windowMs)
+	elapsed := float64(now.UnixMilli()-window*windowMs) / float64(windowMs)
+
+	previous := r.counter(fmt.Sprintf("%s:%d", key, window-1), windowEnd)
+	previousCount, err := previous.Get()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	carried := uint64(math.Floor(float64(previousCount) * (1 - elapsed)))
+	if carried >= r.config.Limit {
+		return RateLimitResult{RetryAfter: r.slidingRetryAfter(previousCount, 0, elapsed)}, nil
+	}
+
+	current := r.counter(fmt.Sprintf("%s:%d", key, window), windowEnd.Add(r.config.Window))
+	currentCount, incremented, err := current.IncrementWithLimit(1, r.config.Limit-carried)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if incremented {
+		return RateLimitResult{Allowed: true}, nil
+	}
+	return RateLimitResult{RetryAfter: r.slidingRetryAfter(previousCount, currentCount, elapsed)}, nil
+}
+
+// slidingRetryAfter estimates when enough of the previous window slides out to free one call
+func (r RateLimiter) slidingRetryAfter(previousCount uint64, currentCount uint64, elapsed float64) time.Duration {
+	remainingWindow := time.Duration(float64(r.config.Window) * (1 - elapsed))
+	if previousCount == 0 || currentCount >= r.config.Limit {
+		return remainingWindow
+	}
+
+	excess := float64(previousCount)*(1-elapsed) + float64(currentCount) + 1 - float64(r.config.Limit)
+	wait := time.Duration(excess / float64(previousCount) * float64(r.config.Window))
+	if wait <= 0 || wait > remainingWindow {
+		return remainingWindow
+	}
+	return wait
+}
+
+// tokenBucket implements GCRA: the counter holds the theoretical arrival time in unix milliseconds,
+// each call adds one emission interval and is allowed while it stays within the bucket capacity
+func (r RateLimiter) tokenBucket(key string, now time.Time) (RateLimitResult, error) {
+	interval := uint64(r.config.Window.Milliseconds()) / r.config.Limit
+	if interval == 0 {
+		interval = 1
+	}
+
+	nowMs := uint64(now.UnixMilli())
+	capacity := interval * r.config.Limit
+	c := r.counter(key, now.Add(2*r.config.Window))
+
+	tat, err := c.Get()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if tat < nowMs {
+		// an idle bucket is full, catch the arrival time up to now. concurrent callers
+		// cannot overshoot since the limit caps the value at now
+		tat, _, err = c.IncrementWithLimit(nowMs-tat, nowMs)
+		if err != nil {
+			return RateLimitResult{}, err
+		}
+	}
+
+	tat, incremented, err := c.IncrementWithLimit(interval, nowMs+capacity)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if incremented {
+		return RateLimitResult{Allowed: true}, nil
+	}
+
+	retryAfter := time.Duration(tat+interval-nowMs-capacity) * time.Millisecond
+	if retryAfter <= 0 {
+		retryAfter = time.Duration(interval) * time.Millisecond
+	}
+	return RateLimitResult{RetryAfter: retryAfter}, nil
+}
@@ -0,0 +1,136 @@
+package polycode
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeCounters keeps counter values in memory with the sidecar semantics, a limit of 0 is unlimited
+type fakeCounters map[string]uint64
+
+type fakeCounter struct {
+	values fakeCounters
+	name   string
+}
+
+func (c fakeCounter) Get() (uint64, error) {
+	return c.values[c.name], nil
+}
+
+func (c fakeCounter) IncrementWithLimit(count uint64, limit uint64) (uint64, bool, error) {
+	value := c.values[c.name]
+	if limit != 0 && value+count > limit {
+		return value, false, nil
+	}
+
+	c.values[c.name] = value + count
+	return value + count, true, nil
+}
+
+func newTestRateLimiter(config RateLimit) (RateLimiter, fakeCounters) {
+	values := make(fakeCounters)
+	return RateLimiter{
+		name:   "test",
+		config: config,
+		newCounter: func(name string, expireAt time.Time) rateLimitCounter {
+			return fakeCounter{values: values, name: name}
+		},
+	}, values
+}
+
+type rateLimitStep struct {
+	at         time.Duration
+	allowed    bool
+	retryAfter time.Duration
+}
+
+func runRateLimitSteps(t *testing.T, r RateLimiter, steps []rateLimitStep) {
+	t.Helper()
+
+	start := time.UnixMilli(1_000_000)
+	for i, step := range steps {
+		res, err := r.check("key", start.Add(step.at))
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+
+		if res.Allowed != step.allowed {
+			t.Errorf("step %d at %s: allowed = %v, want %v", i, step.at, res.Allowed, step.allowed)
+		}
+
+		if !step.allowed && res.RetryAfter != step.retryAfter {
+			t.Errorf("step %d at %s: retry after = %s, want %s", i, step.at, res.RetryAfter, step.retryAfter)
+		}
+	}
+}
+
+func TestRateLimitFixedWindow(t *testing.T) {
+	r, _ := newTestRateLimiter(RateLimit{Algorithm: FixedWindow, Limit: 2, Window: time.Second})
+	runRateLimitSteps(t, r, []rateLimitStep{
+		{at: 0, allowed: true},
+		{at: 100 * time.Millisecond, allowed: true},
+		{at: 400 * time.Millisecond, allowed: false, retryAfter: 600 * time.Millisecond},
+		{at: time.Second, allowed: true},
+		{at: 1500 * time.Millisecond, allowed: true},
+		{at: 1999 * time.Millisecond, allowed: false, retryAfter: time.Millisecond},
+	})
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	r, values := newTestRateLimiter(RateLimit{Algorithm: SlidingWindow, Limit: 4, Window: time.Second})
+
+	// the previous window was full, half of it still overlaps the sliding window at 500ms
+	window := time.UnixMilli(1_000_000).UnixMilli() / 1000
+	values[fmt.Sprintf("key:%d", window-1)] = 4
+
+	runRateLimitSteps(t, r, []rateLimitStep{
+		{at: 500 * time.Millisecond, allowed: true},
+		{at: 500 * time.Millisecond, allowed: true},
+		{at: 500 * time.Millisecond, allowed: false, retryAfter: 250 * time.Millisecond},
+		{at: 750 * time.Millisecond, allowed: true},
+		{at: 750 * time.Millisecond, allowed: false, retryAfter: 250 * time.Millisecond},
+	})
+}
+
+func TestRateLimitSlidingWindowCarriedOver(t *testing.T) {
+	r, values := newTestRateLimiter(RateLimit{Algorithm: SlidingWindow, Limit: 4, Window: time.Second})
+
+	window := time.UnixMilli(1_000_000).UnixMilli() / 1000
+	values[fmt.Sprintf("key:%d", window-1)] = 8
+
+	// 8 * (1 - 0.25) = 6 calls still count, one call fits again once 8 * (1 - elapsed) drops to 3
+	runRateLimitSteps(t, r, []rateLimitStep{
+		{at: 250 * time.Millisecond, allowed: false, retryAfter: 375 * time.Millisecond},
+		{at: 625 * time.Millisecond, allowed: true},
+	})
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	r, _ := newTestRateLimiter(RateLimit{Algorithm: TokenBucket, Limit: 2, Window: 2 * time.Second})
+	runRateLimitSteps(t, r, []rateLimitStep{
+		{at: 0, allowed: true},
+		{at: 0, allowed: true},
+		{at: 0, allowed: false, retryAfter: time.Second},
+		{at: 500 * time.Millisecond, allowed: false, retryAfter: 500 * time.Millisecond},
+		{at: time.Second, allowed: true},
+		{at: time.Second, allowed: false, retryAfter: time.Second},
+		// an idle bucket refills to its capacity but not beyond
+		{at: 10 * time.Second, allowed: true},
+		{at: 10 * time.Second, allowed: true},
+		{at: 10 * time.Second, allowed: false, retryAfter: time.Second},
+	})
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	for _, config := range []RateLimit{
+		{Algorithm: FixedWindow, Limit: 0, Window: time.Second},
+		{Algorithm: FixedWindow, Limit: 1, Window: 0},
+		{Algorithm: "unknown", Limit: 1, Window: time.Second},
+	} {
+		r, _ := newTestRateLimiter(config)
+		if _, err := r.check("key", time.UnixMilli(1_000_000)); !IsError(err, ErrBadRequest) {
+			t.Errorf("check with %+v: error = %v, want bad request", config, err)
+		}
+	}
+}
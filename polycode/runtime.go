@@ -66,7 +66,7 @@ func StartApp(args ...any) {
 		sendStartApp()
 		log.Printf("client: sidecar notified")
 		log.Printf("client: app %s started on port %d\n", GetClientEnv().AppName, GetClientEnv().AppPort)
-		select {}
+		waitForShutdown()
 	}
 }
 
@@ -108,11 +108,17 @@ func sendStartApp() {
 		log.Fatalf("client: %s\n", err.Error())
 	}
 
+	triggers, err := loadFileTriggers()
+	if err != nil {
+		log.Fatalf("client: %s\n", err.Error())
+	}
+
 	req := StartAppRequest{
-		AppName:  GetClientEnv().AppName,
-		AppPort:  GetClientEnv().AppPort,
-		Services: services,
-		Routes:   loadRoutes(),
+		AppName:      GetClientEnv().AppName,
+		AppPort:      GetClientEnv().AppPort,
+		Services:     services,
+		Routes:       loadRoutes(),
+		FileTriggers: triggers,
 	}
 
 	for {
@@ -141,6 +147,41 @@ func loadRoutes() []RouteData {
 func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent) (evt ServiceCompleteEvent) {
 	taskLogger.Info().Msg(fmt.Sprintf("service started %s.%s", event.Service, event.Method))
 
+	hooks := &exitHooks{}
+	stopped := false
+	defer func() {
+		hooks.runStops()
+
+		// a query replay only rebuilds the workflow state, the task itself has not ended
+		if event.Query != nil {
+			return
+		}
+
+		// a retryable failure with attempts left runs the task again, so its hooks wait for the terminal attempt
+		retrying := evt.IsError && evt.Error.CanRetry && event.RetriesLeft > 0
+		if (!stopped && !retrying) || hooks.completed {
+			hooks.run(taskLogger)
+		}
+	}()
+
+	// a query replay registers its handlers under its own key, so it never touches those of a live run
+	queryKey := event.Meta.TaskId
+	if event.Query != nil {
+		queryKey = queryReplayKey(event.Meta.TaskId)
+	}
+
+	// runs after the recover below so that a query replay is answered whether the workflow
+	// suspended or completed, and before the handlers are removed
+	defer workflowQueries.remove(queryKey)
+	defer func() {
+		if event.Query != nil {
+			query := *event.Query
+			query.TaskId = queryKey
+			res := runQuery(ctx, taskLogger, query)
+			evt.Query = &res
+		}
+	}()
+
 	defer func() {
 		// Recover from panic and check for a specific error
 		if r := recover(); r != nil {
@@ -148,6 +189,7 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 
 			if ok {
 				if errors.Is(recovered, ErrTaskStopped) {
+					stopped = true
 					taskLogger.Info().Msg("service stopped")
 					evt = ValueToServiceComplete(nil)
 				} else {
@@ -155,7 +197,7 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 					fmt.Printf("stack trace %s\n", stackTrace)
 
 					taskLogger.Error().Msg(recovered.Error())
-					err2 := ErrInternal.Wrap(recovered)
+					err2 := ErrInternal.Wrap(recovered).Retry(false)
 					evt = ErrorToServiceComplete(err2, stackTrace)
 				}
 			} else {
@@ -164,7 +206,7 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 
 				errorStr := fmt.Sprintf("recoverted %v", r)
 				taskLogger.Error().Msg(errorStr)
-				err2 := ErrInternal.Wrap(fmt.Errorf(errorStr))
+				err2 := ErrInternal.Wrap(fmt.Errorf(errorStr)).Retry(false)
 				evt = ErrorToServiceComplete(err2, stackTrace)
 			}
 		}
@@ -190,28 +232,40 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 
 	err = ConvertType(event.Input, inputObj)
 	if err != nil {
-		err2 := ErrBadRequest.Wrap(err)
+		err2 := ErrBadRequest.Wrap(err).Retry(false)
 		taskLogger.Error().Msg(err2.Error())
 		return ErrorToServiceComplete(err2, "")
 	}
 
 	err = currentValidator.Validate(inputObj)
 	if err != nil {
-		err2 := ErrBadRequest.Wrap(err)
+		err2 := ErrBadRequest.Wrap(err).Retry(false)
 		taskLogger.Error().Msg(err2.Error())
 		return ErrorToServiceComplete(err2, "")
 	}
 
+	// a query replay is not cancellable and must not replace the cancel func of a live run
+	taskCtx := context.WithoutCancel(ctx)
+	if event.Query == nil {
+		var done func()
+		taskCtx, done = runningTasks.start(ctx, event.Meta.TaskId, event.IsCancelled)
+		defer done()
+	}
+
 	ctxImpl := &ContextImpl{
-		ctx:           ctx,
+		ctx:           taskCtx,
 		sessionId:     event.SessionId,
 		dataStore:     newDatabase(serviceClient, event.SessionId),
-		fileStore:     newFileStore(serviceClient, event.SessionId),
+		fileStore:     newFileStore(serviceClient, event.SessionId, hooks),
 		config:        AppConfig{},
 		serviceClient: serviceClient,
 		logger:        taskLogger,
 		meta:          event.Meta,
 		authCtx:       event.AuthContext,
+		hooks:         hooks,
+		permitSeq:     newPermitSequence(),
+		isWorkflow:    meta.IsWorkflow,
+		queryKey:      queryKey,
 	}
 
 	var ret any
@@ -226,7 +280,19 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 	}
 
 	if err != nil {
-		err2 := ErrServiceExecError.Wrap(err)
+		// polycode errors keep their module and error number so that task options can match them
+		err2, ok := AsError(err)
+		if !ok {
+			err2 = ErrServiceExecError.Wrap(err)
+		} else {
+			switch err.(type) {
+			case Error, *Error:
+			default:
+				// keep the context added by the errors wrapping the polycode error
+				err2.CauseBy = err.Error()
+			}
+		}
+		err2 = err2.Retry(IsRetryable(err))
 		taskLogger.Error().Msg(err2.Error())
 		return ErrorToServiceComplete(err2, "")
 	}
@@ -241,6 +307,17 @@ func runService(ctx context.Context, taskLogger Logger, event ServiceStartEvent)
 func runApi(ctx context.Context, taskLogger Logger, event ApiStartEvent) (evt ApiCompleteEvent) {
 	taskLogger.Info().Msg(fmt.Sprintf("api started %s %s", event.Request.Method, event.Request.Path))
 
+	hooks := &exitHooks{}
+	stopped := false
+	defer func() {
+		hooks.runStops()
+
+		// api responses are returned to the caller as is and never retried, any end but suspension is terminal
+		if !stopped || hooks.completed {
+			hooks.run(taskLogger)
+		}
+	}()
+
 	defer func() {
 		// Recover from panic and check for a specific error
 		if r := recover(); r != nil {
@@ -248,6 +325,7 @@ func runApi(ctx context.Context, taskLogger Logger, event ApiStartEvent) (evt Ap
 
 			if ok {
 				if errors.Is(recovered, ErrTaskStopped) {
+					stopped = true
 					taskLogger.Info().Msg("api stopped")
 					evt = ApiCompleteEvent{
 						Response: ApiResponse{
@@ -298,15 +376,20 @@ func runApi(ctx context.Context, taskLogger Logger, event ApiStartEvent) (evt Ap
 	}
 
 	ctxImpl := &ContextImpl{
-		ctx:           ctx,
+		// apis are not cancellable, detach so cancellation scopes do not fire when the request ends
+		ctx:           context.WithoutCancel(ctx),
 		sessionId:     event.SessionId,
 		dataStore:     newDatabase(serviceClient, event.SessionId),
-		fileStore:     newFileStore(serviceClient, event.SessionId),
+		fileStore:     newFileStore(serviceClient, event.SessionId, hooks),
 		config:        AppConfig{},
 		serviceClient: serviceClient,
 		logger:        taskLogger,
 		meta:          event.Meta,
 		authCtx:       event.AuthContext,
+		hooks:         hooks,
+		permitSeq:     newPermitSequence(),
+		isWorkflow:    true,
+		queryKey:      event.Meta.TaskId,
 	}
 
 	newCtx := context.WithValue(ctx, "polycode.context", ctxImpl)
@@ -0,0 +1,103 @@
+package polycode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SagaAction performs one step of a saga
+type SagaAction func() (any, error)
+
+// SagaCompensation undoes a completed step. It returns a Future so that compensations can be
+// started together when parallel compensation is enabled, e.g.
+//
+//	func() *Future { return ctx.Service("inventory").Get().RequestReplyAsync(options, "Release", input) }
+type SagaCompensation func() *Future
+
+type sagaStep struct {
+	action     SagaAction
+	compensate SagaCompensation
+}
+
+// Saga runs a sequence of steps and, when a step fails, compensates the completed steps in reverse order.
+// Step outputs and sequential compensations are recorded with Memo, so a replayed workflow
+// resumes the saga where it stopped instead of repeating finished work.
+type Saga struct {
+	ctx      WorkflowContext
+	steps    []sagaStep
+	parallel bool
+}
+
+func NewSaga(ctx WorkflowContext) *Saga {
+	return &Saga{ctx: ctx}
+}
+
+// ParallelCompensation starts all compensations at once and waits for them together
+func (s *Saga) ParallelCompensation(parallel bool) *Saga {
+	s.parallel = parallel
+	return s
+}
+
+// Step adds a step, compensate may be nil for steps that need no undo
+func (s *Saga) Step(action SagaAction, compensate SagaCompensation) *Saga {
+	s.steps = append(s.steps, sagaStep{action: action, compensate: compensate})
+	return s
+}
+
+// Run executes the steps in order and returns their responses. On failure the returned error is
+// ErrSagaCompensated, or ErrSagaCompensationFailed when an undo did not succeed.
+func (s *Saga) Run() ([]Response, error) {
+	responses := make([]Response, 0, len(s.steps))
+	for i, step := range s.steps {
+		res := s.ctx.Memo(func() (any, error) {
+			return step.action()
+		})
+
+		if res.IsError() {
+			fmt.Printf("client: saga step %d failed: %v\n", i, res.error)
+			return responses, s.compensate(i, res.error)
+		}
+
+		responses = append(responses, res)
+	}
+
+	return responses, nil
+}
+
+func (s *Saga) compensate(failed int, cause error) error {
+	if s.parallel {
+		var futures []*Future
+		var indexes []int
+		for i := failed - 1; i >= 0; i-- {
+			if s.steps[i].compensate == nil {
+				continue
+			}
+
+			futures = append(futures, s.steps[i].compensate())
+			indexes = append(indexes, i)
+		}
+
+		for j, res := range s.ctx.AwaitAll(futures...) {
+			if res.IsError() {
+				return ErrSagaCompensationFailed.With(failed, indexes[j]).Wrap(errors.Join(cause, res.error))
+			}
+		}
+	} else {
+		for i := failed - 1; i >= 0; i-- {
+			compensate := s.steps[i].compensate
+			if compensate == nil {
+				continue
+			}
+
+			res := s.ctx.Memo(func() (any, error) {
+				return compensate().Get().GetAny()
+			})
+
+			if res.IsError() {
+				return ErrSagaCompensationFailed.With(failed, i).Wrap(errors.Join(cause, res.error))
+			}
+		}
+	}
+
+	return ErrSagaCompensated.With(failed).Wrap(cause)
+}
@@ -0,0 +1,112 @@
+package polycode
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops a fire while the previous run is still in progress
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapBufferOne queues at most one fire until the previous run completes
+	OverlapBufferOne OverlapPolicy = "bufferOne"
+	// OverlapAllow starts every fire regardless of running executions
+	OverlapAllow OverlapPolicy = "allow"
+	// OverlapCancelPrevious cancels the running execution before starting the new one
+	OverlapCancelPrevious OverlapPolicy = "cancelPrevious"
+)
+
+// Schedule makes the sidecar invoke a service method periodically as a normal ServiceStartEvent.
+// Exactly one of Cron or Interval must be set, the fire time is available from ContextMeta.ScheduledTime.
+type Schedule struct {
+	Method   string        `json:"method"`
+	Cron     string        `json:"cron"`
+	Interval time.Duration `json:"interval"`
+	Timezone string        `json:"timezone"`
+	Jitter   time.Duration `json:"jitter"`
+	Overlap  OverlapPolicy `json:"overlap"`
+	Input    any           `json:"input"`
+}
+
+func CronSchedule(expr string) Schedule {
+	return Schedule{Cron: expr, Timezone: "UTC", Overlap: OverlapSkip}
+}
+
+func IntervalSchedule(interval time.Duration) Schedule {
+	return Schedule{Interval: interval, Timezone: "UTC", Overlap: OverlapSkip}
+}
+
+func (s Schedule) InTimezone(timezone string) Schedule {
+	s.Timezone = timezone
+	return s
+}
+
+// WithJitter delays each fire by a random duration up to jitter
+func (s Schedule) WithJitter(jitter time.Duration) Schedule {
+	s.Jitter = jitter
+	return s
+}
+
+func (s Schedule) WithOverlap(policy OverlapPolicy) Schedule {
+	s.Overlap = policy
+	return s
+}
+
+// WithInput sets the input passed to the method on every fire
+func (s Schedule) WithInput(input any) Schedule {
+	s.Input = input
+	return s
+}
+
+func (s Schedule) validate() error {
+	if (s.Cron == "") == (s.Interval <= 0) {
+		return fmt.Errorf("client: schedule of %s must set exactly one of cron or interval", s.Method)
+	}
+
+	if s.Cron != "" {
+		fields := len(strings.Fields(s.Cron))
+		if fields != 5 && fields != 6 && !strings.HasPrefix(s.Cron, "@") {
+			return fmt.Errorf("client: invalid cron expression %s for %s", s.Cron, s.Method)
+		}
+	}
+
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("client: invalid timezone %s for %s: %w", s.Timezone, s.Method, err)
+	}
+
+	switch s.Overlap {
+	case OverlapSkip, OverlapBufferOne, OverlapAllow, OverlapCancelPrevious:
+		return nil
+	default:
+		return fmt.Errorf("client: invalid overlap policy %s for %s", s.Overlap, s.Method)
+	}
+}
+
+var serviceSchedules = make(map[string][]Schedule)
+
+// RegisterSchedule runs service.method on the given schedule
+func RegisterSchedule(service string, method string, schedule Schedule) {
+	log.Printf("client: register schedule %s.%s\n", service, method)
+
+	schedule.Method = method
+	serviceSchedules[service] = append(serviceSchedules[service], schedule)
+}
+
+func loadSchedules(service string) ([]Schedule, error) {
+	schedules := serviceSchedules[service]
+	for _, schedule := range schedules {
+		if err := schedule.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := checkServiceMethod(service, schedule.Method); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,86 @@
+package polycode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SelectCase is one branch of WorkflowContext.Select, either a signal or a timer
+type SelectCase struct {
+	signal *Signal
+	timer  *Timer
+}
+
+func SignalCase(signal Signal) SelectCase {
+	return SelectCase{signal: &signal}
+}
+
+func TimerCase(timer *Timer) SelectCase {
+	return SelectCase{timer: timer}
+}
+
+// selectCases waits until one of the cases fires and returns its index with the signal output.
+// Timer cases return an empty Response.
+func selectCases(sessionId string, serviceClient *ServiceClient, cases []SelectCase) (int, Response) {
+	req := SignalWaitRequest{}
+	for i, c := range cases {
+		if (c.signal == nil) == (c.timer == nil) {
+			return -1, Response{
+				output:  nil,
+				isError: true,
+				error:   ErrBadRequest.Wrap(fmt.Errorf("select case %d must be created with SignalCase or TimerCase", i)),
+			}
+		}
+
+		if c.timer != nil {
+			if c.timer.fired {
+				return i, Response{}
+			}
+			req.TimerIds = append(req.TimerIds, c.timer.id)
+		} else {
+			if c.signal.correlationKey != "" {
+				if req.CorrelationKey != "" && req.CorrelationKey != c.signal.correlationKey {
+					return -1, Response{
+						output:  nil,
+						isError: true,
+						error:   ErrBadRequest.Wrap(errors.New("select signals have different correlation keys")),
+					}
+				}
+				req.CorrelationKey = c.signal.correlationKey
+			}
+			req.SignalNames = append(req.SignalNames, c.signal.name)
+		}
+	}
+
+	output, err := serviceClient.WaitForSignal(sessionId, req)
+	if err != nil {
+		fmt.Printf("client: signal select error: %v\n", err)
+		return -1, Response{
+			output:  nil,
+			isError: true,
+			error:   wrapTaskError(err),
+		}
+	}
+
+	fmt.Printf("client: signal select output: %v\n", output)
+	for i, c := range cases {
+		if c.timer != nil && output.TimerId != "" && c.timer.id == output.TimerId {
+			c.timer.fired = true
+			return i, Response{}
+		}
+
+		if c.signal != nil && output.TimerId == "" && c.signal.name == output.SignalName {
+			return i, Response{
+				output:  output.Output,
+				isError: output.IsError,
+				error:   output.Error,
+			}
+		}
+	}
+
+	return -1, Response{
+		output:  nil,
+		isError: true,
+		error:   ErrTaskExecError.Wrap(errors.New("no select case matched")),
+	}
+}
@@ -3,6 +3,7 @@ package polycode
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 type Response struct {
@@ -94,7 +95,7 @@ func (r RemoteService) RequestReply(options TaskOptions, method string, input an
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
 		}
 	}
 
@@ -106,6 +107,29 @@ func (r RemoteService) RequestReply(options TaskOptions, method string, input an
 	}
 }
 
+// RequestReplyAsync starts the task and returns immediately, use WorkflowContext.AwaitAll or
+// AwaitAny to wait for several futures with a single suspension
+func (r RemoteService) RequestReplyAsync(options TaskOptions, method string, input any) *Future {
+	req := ExecServiceRequest{
+		EnvId:        r.envId,
+		Service:      r.service,
+		TenantId:     r.tenantId,
+		PartitionKey: r.partitionKey,
+		Method:       method,
+		Options:      options,
+		Input:        input,
+	}
+
+	output, err := r.serviceClient.ExecServiceAsync(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: exec task async error: %v\n", err)
+		return failedFuture(r.sessionId, r.serviceClient, wrapTaskError(err))
+	}
+
+	fmt.Printf("client: exec task async output: %v\n", output)
+	return newFuture(r.sessionId, r.serviceClient, output)
+}
+
 func (r RemoteService) Send(options TaskOptions, method string, input any) error {
 	req := ExecServiceRequest{
 		EnvId:         r.envId,
@@ -121,7 +145,7 @@ func (r RemoteService) Send(options TaskOptions, method string, input any) error
 	output, err := r.serviceClient.ExecService(r.sessionId, req)
 	if err != nil {
 		fmt.Printf("client: exec task error: %v\n", err)
-		return ErrTaskExecError.Wrap(err)
+		return wrapTaskError(err)
 	}
 
 	fmt.Printf("client: exec task output: %v\n", output)
@@ -132,6 +156,75 @@ func (r RemoteService) Send(options TaskOptions, method string, input any) error
 	}
 }
 
+// Cancel requests cancellation of a task started on this service
+func (r RemoteService) Cancel(taskId string) error {
+	return cancelTask(r.sessionId, r.serviceClient, taskId)
+}
+
+// QueryWorkflow calls the query handler registered by the workflow task with SetQueryHandler, passing input.
+// A suspended workflow is replayed by the sidecar to answer from its current state.
+func (r RemoteService) QueryWorkflow(taskId string, name string, input any) Response {
+	req := QueryWorkflowRequest{
+		EnvId:        r.envId,
+		Service:      r.service,
+		TenantId:     r.tenantId,
+		PartitionKey: r.partitionKey,
+		TaskId:       taskId,
+		Query:        name,
+		Input:        input,
+	}
+
+	output, err := r.serviceClient.QueryWorkflow(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: query workflow error: %v\n", err)
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   wrapTaskError(err),
+		}
+	}
+
+	fmt.Printf("client: query workflow output: %v\n", output)
+	return Response{
+		output:  output.Output,
+		isError: output.IsError,
+		error:   output.Error,
+	}
+}
+
+// SendAt durably enqueues the call to run at the given time, ScheduleAt is in unix milliseconds.
+// On replay the sidecar returns the task scheduled by the first execution.
+func (r RemoteService) SendAt(at time.Time, options TaskOptions, method string, input any) (ScheduledTask, error) {
+	req := ExecServiceRequest{
+		EnvId:         r.envId,
+		Service:       r.service,
+		TenantId:      r.tenantId,
+		PartitionKey:  r.partitionKey,
+		Method:        method,
+		Options:       options,
+		FireAndForget: true,
+		ScheduleAt:    at.UnixMilli(),
+		Input:         input,
+	}
+
+	output, err := r.serviceClient.ExecService(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: exec task error: %v\n", err)
+		return ScheduledTask{}, wrapTaskError(err)
+	}
+
+	fmt.Printf("client: exec task output: %v\n", output)
+	if output.IsError {
+		return ScheduledTask{}, output.Error
+	}
+
+	return ScheduledTask{taskId: output.TaskId, sessionId: r.sessionId, serviceClient: r.serviceClient}, nil
+}
+
+func (r RemoteService) SendAfter(delay time.Duration, options TaskOptions, method string, input any) (ScheduledTask, error) {
+	return r.SendAt(time.Now().Add(delay), options, method, input)
+}
+
 type RemoteAgentBuilder struct {
 	ctx           context.Context
 	sessionId     string
@@ -186,7 +279,7 @@ func (r RemoteAgent) Call(options TaskOptions, input AgentInput) Response {
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
 		}
 	}
 
@@ -198,6 +291,30 @@ func (r RemoteAgent) Call(options TaskOptions, input AgentInput) Response {
 	}
 }
 
+func (r RemoteAgent) CallAsync(options TaskOptions, input AgentInput) *Future {
+	req := ExecServiceRequest{
+		EnvId:        r.envId,
+		Service:      "agent-service",
+		TenantId:     r.tenantId,
+		PartitionKey: r.agent + ":" + input.SessionKey,
+		Method:       "CallAgent",
+		Options:      options,
+		Headers: map[string]string{
+			AgentNameHeader: r.agent,
+		},
+		Input: input,
+	}
+
+	output, err := r.serviceClient.ExecServiceAsync(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: exec task async error: %v\n", err)
+		return failedFuture(r.sessionId, r.serviceClient, wrapTaskError(err))
+	}
+
+	fmt.Printf("client: exec task async output: %v\n", output)
+	return newFuture(r.sessionId, r.serviceClient, output)
+}
+
 type RemoteApp struct {
 	ctx           context.Context
 	sessionId     string
@@ -221,7 +338,7 @@ func (r RemoteApp) RequestReply(options TaskOptions, method string, input any) R
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
 		}
 	}
 
@@ -233,6 +350,25 @@ func (r RemoteApp) RequestReply(options TaskOptions, method string, input any) R
 	}
 }
 
+func (r RemoteApp) RequestReplyAsync(options TaskOptions, method string, input any) *Future {
+	req := ExecAppRequest{
+		EnvId:   r.envId,
+		AppName: r.appName,
+		Method:  method,
+		Options: options,
+		Input:   input,
+	}
+
+	output, err := r.serviceClient.ExecAppAsync(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: exec task async error: %v\n", err)
+		return failedFuture(r.sessionId, r.serviceClient, wrapTaskError(err))
+	}
+
+	fmt.Printf("client: exec task async output: %v\n", output)
+	return newFuture(r.sessionId, r.serviceClient, output)
+}
+
 func (r RemoteApp) Send(options TaskOptions, method string, input any) error {
 	req := ExecAppRequest{
 		EnvId:         r.envId,
@@ -246,7 +382,7 @@ func (r RemoteApp) Send(options TaskOptions, method string, input any) error {
 	output, err := r.serviceClient.ExecApp(r.sessionId, req)
 	if err != nil {
 		fmt.Printf("client: exec task error: %v\n", err)
-		return ErrTaskExecError.Wrap(err)
+		return wrapTaskError(err)
 	}
 
 	fmt.Printf("client: exec task output: %v\n", output)
@@ -257,6 +393,51 @@ func (r RemoteApp) Send(options TaskOptions, method string, input any) error {
 	}
 }
 
+func (r RemoteApp) SendAt(at time.Time, options TaskOptions, method string, input any) (ScheduledTask, error) {
+	req := ExecAppRequest{
+		EnvId:         r.envId,
+		AppName:       r.appName,
+		Method:        method,
+		Options:       options,
+		FireAndForget: true,
+		ScheduleAt:    at.UnixMilli(),
+		Input:         input,
+	}
+
+	output, err := r.serviceClient.ExecApp(r.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: exec task error: %v\n", err)
+		return ScheduledTask{}, wrapTaskError(err)
+	}
+
+	fmt.Printf("client: exec task output: %v\n", output)
+	if output.IsError {
+		return ScheduledTask{}, output.Error
+	}
+
+	return ScheduledTask{taskId: output.TaskId, sessionId: r.sessionId, serviceClient: r.serviceClient}, nil
+}
+
+func (r RemoteApp) SendAfter(delay time.Duration, options TaskOptions, method string, input any) (ScheduledTask, error) {
+	return r.SendAt(time.Now().Add(delay), options, method, input)
+}
+
+// ScheduledTask is the handle of a delayed Send
+type ScheduledTask struct {
+	taskId        string
+	sessionId     string
+	serviceClient *ServiceClient
+}
+
+func (t ScheduledTask) TaskId() string {
+	return t.taskId
+}
+
+// Cancel drops the pending invocation, it has no effect once the task started
+func (t ScheduledTask) Cancel() error {
+	return cancelTask(t.sessionId, t.serviceClient, t.taskId)
+}
+
 type RemoteController struct {
 	ctx           context.Context
 	sessionId     string
@@ -312,11 +493,13 @@ type Memo struct {
 	ctx           context.Context
 	sessionId     string
 	serviceClient *ServiceClient
+	key           string
 	getter        func() (any, error)
 }
 
 func (f Memo) Get() Response {
 	req1 := ExecFuncRequest{
+		Key:   f.key,
 		Input: nil,
 	}
 
@@ -326,7 +509,16 @@ func (f Memo) Get() Response {
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
+		}
+	}
+
+	if res1.IsCompleted && f.key != "" && res1.Key != f.key {
+		fmt.Printf("client: memo key mismatch, expected %s got %s\n", f.key, res1.Key)
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   ErrNonDeterministic.With(f.key, res1.Key),
 		}
 	}
 
@@ -355,6 +547,7 @@ func (f Memo) Get() Response {
 	}
 
 	req2 := ExecFuncResult{
+		Key:     f.key,
 		Input:   nil,
 		Output:  response.output,
 		IsError: response.isError,
@@ -367,7 +560,7 @@ func (f Memo) Get() Response {
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
 		}
 	}
 
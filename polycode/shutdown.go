@@ -0,0 +1,68 @@
+package polycode
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// shutdownRegistry holds the funcs to run when the app receives a termination signal
+type shutdownRegistry struct {
+	mu    sync.Mutex
+	next  uint64
+	hooks map[uint64]func()
+}
+
+var shutdownHooks = &shutdownRegistry{hooks: make(map[uint64]func())}
+
+// add registers fn and returns a func that unregisters it
+func (r *shutdownRegistry) add(fn func()) func() {
+	r.mu.Lock()
+	id := r.next
+	r.next++
+	r.hooks[id] = fn
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.hooks, id)
+		r.mu.Unlock()
+	}
+}
+
+func (r *shutdownRegistry) run() {
+	r.mu.Lock()
+	hooks := make([]func(), 0, len(r.hooks))
+	for _, fn := range r.hooks {
+		hooks = append(hooks, fn)
+	}
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, fn := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("client: shutdown hook panicked: %v\n", r)
+				}
+			}()
+			fn()
+		}()
+	}
+	wg.Wait()
+}
+
+// waitForShutdown blocks until SIGINT or SIGTERM and then runs the shutdown hooks
+func waitForShutdown() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	sig := <-signals
+	log.Printf("client: received %s, shutting down\n", sig)
+	shutdownHooks.run()
+	log.Printf("client: shutdown complete")
+}
@@ -1,16 +1,28 @@
 package polycode
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Signal struct {
-	name          string
-	sessionId     string
-	serviceClient *ServiceClient
+	name           string
+	correlationKey string
+	sessionId      string
+	serviceClient  *ServiceClient
+}
+
+// Correlate registers key with the awaiting workflow so that external systems can
+// emit the signal with EmitByKey without knowing the task id
+func (s Signal) Correlate(key string) Signal {
+	s.correlationKey = key
+	return s
 }
 
 func (s *Signal) Await() Response {
 	req := SignalWaitRequest{
-		SignalName: s.name,
+		SignalName:     s.name,
+		CorrelationKey: s.correlationKey,
 	}
 
 	output, err := s.serviceClient.WaitForSignal(s.sessionId, req)
@@ -19,7 +31,7 @@ func (s *Signal) Await() Response {
 		return Response{
 			output:  nil,
 			isError: true,
-			error:   ErrTaskExecError.Wrap(err),
+			error:   wrapTaskError(err),
 		}
 	}
 
@@ -31,6 +43,53 @@ func (s *Signal) Await() Response {
 	}
 }
 
+// AwaitUntil waits for the signal or for the timer to fire, whichever happens first.
+// The returned bool is true when the timer fired before the signal arrived.
+func (s *Signal) AwaitUntil(timer *Timer) (Response, bool) {
+	req := SignalWaitRequest{
+		SignalName:     s.name,
+		TimerIds:       []string{timer.id},
+		CorrelationKey: s.correlationKey,
+	}
+
+	output, err := s.serviceClient.WaitForSignal(s.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: signal await error: %v\n", err)
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   wrapTaskError(err),
+		}, false
+	}
+
+	if output.TimerId != "" {
+		timer.fired = true
+		return Response{}, true
+	}
+
+	fmt.Printf("client: signal await output: %v\n", output)
+	return Response{
+		output:  output.Output,
+		isError: output.IsError,
+		error:   output.Error,
+	}, false
+}
+
+// AwaitWithTimeout waits for the signal for at most d, the returned bool is true on timeout
+func (s *Signal) AwaitWithTimeout(d time.Duration) (Response, bool) {
+	timer, err := startTimer(s.sessionId, s.serviceClient, time.Now().Add(d))
+	if err != nil {
+		// startTimer already wraps the failure in ErrTaskExecError
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   err.(Error),
+		}, false
+	}
+
+	return s.AwaitUntil(&timer)
+}
+
 func (s *Signal) EmitValue(taskId string, data any) error {
 	req := SignalEmitRequest{
 		TaskId:     taskId,
@@ -52,3 +111,26 @@ func (s *Signal) EmitError(taskId string, err Error) error {
 
 	return s.serviceClient.EmitSignal(s.sessionId, req)
 }
+
+// EmitByKey emits the signal to the workflow awaiting it with the given correlation key
+func (s *Signal) EmitByKey(key string, data any) error {
+	req := SignalEmitRequest{
+		CorrelationKey: key,
+		SignalName:     s.name,
+		Output:         data,
+		IsError:        false,
+	}
+
+	return s.serviceClient.EmitSignal(s.sessionId, req)
+}
+
+func (s *Signal) EmitErrorByKey(key string, err Error) error {
+	req := SignalEmitRequest{
+		CorrelationKey: key,
+		SignalName:     s.name,
+		IsError:        true,
+		Error:          err,
+	}
+
+	return s.serviceClient.EmitSignal(s.sessionId, req)
+}
@@ -0,0 +1,78 @@
+package polycode
+
+import "fmt"
+
+// SignalChannel is a durable, ordered queue of values emitted to a signal. Values emitted
+// with Signal.EmitValue or EmitByKey are kept until received, and the sidecar records every
+// receive so that a resumed workflow replays the same sequence.
+type SignalChannel struct {
+	ctx            WorkflowContext
+	name           string
+	correlationKey string
+	sessionId      string
+	serviceClient  *ServiceClient
+}
+
+func (s SignalChannel) Correlate(key string) SignalChannel {
+	s.correlationKey = key
+	return s
+}
+
+// Receive returns the next value, suspending the workflow until one is emitted
+func (s SignalChannel) Receive() Response {
+	res, _ := s.receive(true)
+	return res
+}
+
+// TryReceive returns the next value if one is queued without waiting
+func (s SignalChannel) TryReceive() (Response, bool) {
+	return s.receive(false)
+}
+
+// Len returns the number of queued values. The result is memoized, so a replay sees the length
+// observed by the first execution rather than the live queue.
+func (s SignalChannel) Len() (int, error) {
+	return MemoT(s.ctx, "@signal-len:"+s.name+":"+s.correlationKey, func() (int, error) {
+		req := SignalChannelLenRequest{
+			SignalName:     s.name,
+			CorrelationKey: s.correlationKey,
+		}
+
+		res, err := s.serviceClient.SignalChannelLen(s.sessionId, req)
+		if err != nil {
+			fmt.Printf("client: signal channel len error: %v\n", err)
+			return 0, err
+		}
+
+		return res.Length, nil
+	})
+}
+
+func (s SignalChannel) receive(block bool) (Response, bool) {
+	req := SignalReceiveRequest{
+		SignalName:     s.name,
+		CorrelationKey: s.correlationKey,
+		Block:          block,
+	}
+
+	output, err := s.serviceClient.ReceiveSignal(s.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: signal receive error: %v\n", err)
+		return Response{
+			output:  nil,
+			isError: true,
+			error:   wrapTaskError(err),
+		}, false
+	}
+
+	if !output.HasValue {
+		return Response{}, false
+	}
+
+	fmt.Printf("client: signal receive output: %v\n", output)
+	return Response{
+		output:  output.Output,
+		isError: output.IsError,
+		error:   output.Error,
+	}, true
+}
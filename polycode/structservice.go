@@ -0,0 +1,221 @@
+package polycode
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+)
+
+var (
+	serviceContextType  = reflect.TypeOf((*ServiceContext)(nil)).Elem()
+	workflowContextType = reflect.TypeOf((*WorkflowContext)(nil)).Elem()
+	errorType           = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// MethodDescriber may be implemented by a struct registered with RegisterStruct to describe its methods
+type MethodDescriber interface {
+	Describe(method string) string
+}
+
+type structMethod struct {
+	fn         reflect.Value
+	inputType  reflect.Type
+	outputType reflect.Type
+	isWorkflow bool
+}
+
+// structService implements Service by dispatching to the methods of a struct through reflection
+type structService struct {
+	name    string
+	impl    any
+	methods map[string]structMethod
+	names   []string
+}
+
+// RegisterStruct registers impl as the service name. Every exported method of shape
+// func(ServiceContext, In) (Out, error) becomes a service method and every method of shape
+// func(WorkflowContext, In) (Out, error) becomes a workflow, both named after the Go method.
+// In may be a struct or a pointer to one, other methods are ignored. impl must be a pointer so that
+// methods with pointer receivers are found.
+func RegisterStruct(name string, impl any) error {
+	if serviceMap[name] != nil {
+		return fmt.Errorf("client: service %s already registered", name)
+	}
+
+	service, err := newStructService(name, impl)
+	if err != nil {
+		return err
+	}
+
+	RegisterService(service)
+	return nil
+}
+
+func newStructService(name string, impl any) (*structService, error) {
+	if impl == nil {
+		return nil, fmt.Errorf("client: service %s has no implementation", name)
+	}
+
+	s := &structService{
+		name:    name,
+		impl:    impl,
+		methods: make(map[string]structMethod),
+	}
+
+	v := reflect.ValueOf(impl)
+	t := v.Type()
+	if t.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("client: service %s implementation must be a non-nil pointer, got %s", name, t)
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		fn := v.Method(i)
+		fnType := fn.Type()
+		if fnType.NumIn() != 2 || fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+			continue
+		}
+
+		var isWorkflow bool
+		switch fnType.In(0) {
+		case serviceContextType:
+			isWorkflow = false
+		case workflowContextType:
+			isWorkflow = true
+		default:
+			continue
+		}
+
+		methodName := t.Method(i).Name
+		s.methods[methodName] = structMethod{
+			fn:         fn,
+			inputType:  fnType.In(1),
+			outputType: fnType.Out(0),
+			isWorkflow: isWorkflow,
+		}
+		s.names = append(s.names, methodName)
+		log.Printf("client: service %s found method %s\n", name, methodName)
+	}
+
+	if len(s.names) == 0 {
+		return nil, fmt.Errorf("client: service %s has no methods of shape func(ServiceContext|WorkflowContext, In) (Out, error)", name)
+	}
+	return s, nil
+}
+
+func (s *structService) method(method string) (structMethod, error) {
+	m, ok := s.methods[method]
+	if !ok {
+		return structMethod{}, fmt.Errorf("client: method %s not found in service %s", method, s.name)
+	}
+	return m, nil
+}
+
+// newValue returns a pointer to a zero value of t, or of the type t points to
+func newValue(t reflect.Type) any {
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface()
+	}
+	return reflect.New(t).Interface()
+}
+
+func (s *structService) GetName() string {
+	return s.name
+}
+
+func (s *structService) GetDescription(method string) (string, error) {
+	if _, err := s.method(method); err != nil {
+		return "", err
+	}
+
+	if describer, ok := s.impl.(MethodDescriber); ok {
+		return describer.Describe(method), nil
+	}
+	return "", nil
+}
+
+func (s *structService) GetInputType(method string) (any, error) {
+	m, err := s.method(method)
+	if err != nil {
+		return nil, err
+	}
+	return newValue(m.inputType), nil
+}
+
+func (s *structService) GetOutputType(method string) (any, error) {
+	m, err := s.method(method)
+	if err != nil {
+		return nil, err
+	}
+	return newValue(m.outputType), nil
+}
+
+func (s *structService) IsWorkflow(method string) bool {
+	return s.methods[method].isWorkflow
+}
+
+func (s *structService) ExecuteService(ctx ServiceContext, method string, input any) (any, error) {
+	if method == "@definition" {
+		return s.names, nil
+	}
+
+	m, err := s.method(method)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.isWorkflow {
+		return nil, fmt.Errorf("client: method %s of service %s is a workflow", method, s.name)
+	}
+	return s.call(m, reflect.ValueOf(&ctx).Elem(), input)
+}
+
+func (s *structService) ExecuteWorkflow(ctx WorkflowContext, method string, input any) (any, error) {
+	m, err := s.method(method)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.isWorkflow {
+		return nil, fmt.Errorf("client: method %s of service %s is not a workflow", method, s.name)
+	}
+	return s.call(m, reflect.ValueOf(&ctx).Elem(), input)
+}
+
+func (s *structService) call(m structMethod, ctx reflect.Value, input any) (any, error) {
+	arg, err := structArg(m.inputType, input)
+	if err != nil {
+		return nil, ErrBadRequest.Wrap(err)
+	}
+
+	out := m.fn.Call([]reflect.Value{ctx, arg})
+	if !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
+
+// structArg adapts input, normally the pointer returned by GetInputType, to the method parameter type
+func structArg(t reflect.Type, input any) (reflect.Value, error) {
+	if input == nil {
+		return reflect.Zero(t), nil
+	}
+
+	// unwrap the pointer first, otherwise an interface parameter such as any would receive the pointer
+	v := reflect.ValueOf(input)
+	if t.Kind() != reflect.Ptr && v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Type().AssignableTo(t) {
+		return v.Elem(), nil
+	}
+
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+
+	converted := newValue(t)
+	if err := ConvertType(input, converted); err != nil {
+		return reflect.Value{}, err
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return reflect.ValueOf(converted), nil
+	}
+	return reflect.ValueOf(converted).Elem(), nil
+}
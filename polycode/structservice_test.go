@@ -0,0 +1,196 @@
+package polycode
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type structTestInput struct {
+	Value int `json:"value"`
+}
+
+type structTestOutput struct {
+	Value int `json:"value"`
+}
+
+var errStructTest = errors.New("struct test failure")
+
+type structTestService struct {
+	calls int
+}
+
+func (s *structTestService) Double(ctx ServiceContext, in structTestInput) (structTestOutput, error) {
+	s.calls++
+	return structTestOutput{Value: in.Value * 2}, nil
+}
+
+func (s *structTestService) Negate(ctx WorkflowContext, in *structTestInput) (*structTestOutput, error) {
+	return &structTestOutput{Value: -in.Value}, nil
+}
+
+func (s *structTestService) Echo(ctx ServiceContext, in any) (any, error) {
+	return in, nil
+}
+
+func (s *structTestService) Fail(ctx ServiceContext, in structTestInput) (structTestOutput, error) {
+	return structTestOutput{}, errStructTest
+}
+
+func (s *structTestService) Describe(method string) string {
+	return "describes " + method
+}
+
+func (s *structTestService) Helper(in structTestInput) int {
+	return in.Value
+}
+
+func (s *structTestService) WrongContext(ctx RawContext, in structTestInput) (structTestOutput, error) {
+	return structTestOutput{}, nil
+}
+
+type structTestNoMethods struct{}
+
+func (structTestNoMethods) Helper() {}
+
+func decodeInput(t *testing.T, s *structService, method string, input any) any {
+	t.Helper()
+
+	obj, err := s.GetInputType(method)
+	if err != nil {
+		t.Fatalf("GetInputType(%s): %v", method, err)
+	}
+
+	if err = ConvertType(input, obj); err != nil {
+		t.Fatalf("ConvertType(%s): %v", method, err)
+	}
+	return obj
+}
+
+func TestStructServiceDiscovery(t *testing.T) {
+	s, err := newStructService("test", &structTestService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.ExecuteService(nil, "@definition", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Double", "Echo", "Fail", "Negate"}
+	if got := res.([]string); !slices.Equal(got, want) {
+		t.Errorf("@definition = %v, want %v", got, want)
+	}
+
+	if s.IsWorkflow("Double") || !s.IsWorkflow("Negate") {
+		t.Errorf("IsWorkflow(Double) = %v, IsWorkflow(Negate) = %v", s.IsWorkflow("Double"), s.IsWorkflow("Negate"))
+	}
+
+	types := []struct {
+		method string
+		input  any
+		output any
+	}{
+		{method: "Double", input: &structTestInput{}, output: &structTestOutput{}},
+		{method: "Negate", input: &structTestInput{}, output: &structTestOutput{}},
+		{method: "Echo", input: new(any), output: new(any)},
+	}
+
+	for _, tt := range types {
+		input, _ := s.GetInputType(tt.method)
+		output, _ := s.GetOutputType(tt.method)
+		if reflect.TypeOf(input) != reflect.TypeOf(tt.input) || reflect.TypeOf(output) != reflect.TypeOf(tt.output) {
+			t.Errorf("%s: types = %T, %T, want %T, %T", tt.method, input, output, tt.input, tt.output)
+		}
+	}
+
+	description, err := s.GetDescription("Double")
+	if err != nil || description != "describes Double" {
+		t.Errorf("GetDescription(Double) = %q, %v", description, err)
+	}
+}
+
+func TestStructServiceExecute(t *testing.T) {
+	impl := &structTestService{}
+	s, err := newStructService("test", impl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := s.ExecuteService(nil, "Double", decodeInput(t, s, "Double", map[string]any{"value": 4}))
+	if err != nil || out != (structTestOutput{Value: 8}) {
+		t.Errorf("Double = %v, %v", out, err)
+	}
+
+	if impl.calls != 1 {
+		t.Errorf("pointer receiver calls = %d, want 1", impl.calls)
+	}
+
+	out, err = s.ExecuteWorkflow(nil, "Negate", decodeInput(t, s, "Negate", map[string]any{"value": 3}))
+	if err != nil || *out.(*structTestOutput) != (structTestOutput{Value: -3}) {
+		t.Errorf("Negate = %v, %v", out, err)
+	}
+
+	out, err = s.ExecuteService(nil, "Echo", decodeInput(t, s, "Echo", map[string]any{"value": 1.0}))
+	if err != nil || !reflect.DeepEqual(out, map[string]any{"value": 1.0}) {
+		t.Errorf("Echo = %#v, %v", out, err)
+	}
+
+	out, err = s.ExecuteService(nil, "Double", structTestInput{Value: 5})
+	if err != nil || out != (structTestOutput{Value: 10}) {
+		t.Errorf("Double with value input = %v, %v", out, err)
+	}
+
+	if _, err = s.ExecuteService(nil, "Fail", &structTestInput{}); !errors.Is(err, errStructTest) {
+		t.Errorf("Fail error = %v, want %v", err, errStructTest)
+	}
+}
+
+func TestStructServiceErrors(t *testing.T) {
+	s, err := newStructService("test", &structTestService{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = s.ExecuteService(nil, "Missing", nil); err == nil {
+		t.Error("ExecuteService(Missing) succeeded")
+	}
+
+	if _, err = s.GetInputType("Helper"); err == nil {
+		t.Error("GetInputType(Helper) succeeded")
+	}
+
+	if _, err = s.ExecuteService(nil, "Negate", &structTestInput{}); err == nil {
+		t.Error("ExecuteService on a workflow succeeded")
+	}
+
+	if _, err = s.ExecuteWorkflow(nil, "Double", &structTestInput{}); err == nil {
+		t.Error("ExecuteWorkflow on a service method succeeded")
+	}
+
+	for name, impl := range map[string]any{
+		"nil":        nil,
+		"value":      structTestService{},
+		"nil ptr":    (*structTestService)(nil),
+		"no methods": &structTestNoMethods{},
+	} {
+		if _, err = newStructService("test", impl); err == nil {
+			t.Errorf("newStructService with %s implementation succeeded", name)
+		}
+	}
+}
+
+func TestRegisterStructDuplicate(t *testing.T) {
+	const name = "polycode.test.duplicate"
+	defer delete(serviceMap, name)
+
+	if err := RegisterStruct(name, &structTestService{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterStruct(name, &structTestService{}); err == nil {
+		t.Error("second RegisterStruct succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+package polycode
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timer is a durable timer kept by the sidecar. Awaiting an unfired timer suspends the
+// workflow through ErrTaskStopped, so no process is held while it is pending.
+type Timer struct {
+	id            string
+	fireAt        time.Time
+	fired         bool
+	sessionId     string
+	serviceClient *ServiceClient
+}
+
+func startTimer(sessionId string, serviceClient *ServiceClient, fireAt time.Time) (Timer, error) {
+	req := StartTimerRequest{
+		FireAt: fireAt.UnixMilli(),
+	}
+
+	// on replay the sidecar returns the timer recorded by the first execution
+	res, err := serviceClient.StartTimer(sessionId, req)
+	if err != nil {
+		fmt.Printf("client: start timer error: %v\n", err)
+		return Timer{}, wrapTaskError(err)
+	}
+
+	return Timer{
+		id:            res.TimerId,
+		fireAt:        time.UnixMilli(res.FireAt),
+		fired:         res.IsFired,
+		sessionId:     sessionId,
+		serviceClient: serviceClient,
+	}, nil
+}
+
+func (t *Timer) Id() string {
+	return t.id
+}
+
+func (t *Timer) FireAt() time.Time {
+	return t.fireAt
+}
+
+func (t *Timer) IsFired() bool {
+	return t.fired
+}
+
+// Await blocks until the timer fires
+func (t *Timer) Await() error {
+	if t.fired {
+		return nil
+	}
+
+	req := AwaitTimerRequest{
+		TimerId: t.id,
+	}
+
+	res, err := t.serviceClient.AwaitTimer(t.sessionId, req)
+	if err != nil {
+		fmt.Printf("client: await timer error: %v\n", err)
+		return wrapTaskError(err)
+	}
+
+	if !res.IsFired {
+		return ErrTaskExecError.Wrap(fmt.Errorf("timer %s not fired", t.id))
+	}
+
+	t.fired = true
+	return nil
+}
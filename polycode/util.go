@@ -3,9 +3,11 @@ package polycode
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/invopop/jsonschema"
 	"log"
 	"reflect"
+	"slices"
 )
 
 func ToMap(data map[string][]string) map[string]any {
@@ -143,12 +145,49 @@ func GetMethodDescription(service Service, method string) (MethodDescription, er
 	}, nil
 }
 
+// checkServiceMethod fails unless service is registered and lists method in its definition
+func checkServiceMethod(serviceName string, method string) error {
+	service, err := getService(serviceName)
+	if err != nil {
+		return err
+	}
+
+	res, err := service.ExecuteService(nil, "@definition", nil)
+	if err != nil {
+		return err
+	}
+
+	methods, ok := res.([]string)
+	if !ok {
+		return fmt.Errorf("client: service %s returned an invalid definition", serviceName)
+	}
+
+	if !slices.Contains(methods, method) {
+		return fmt.Errorf("client: method %s not found in service %s", method, serviceName)
+	}
+	return nil
+}
+
 func ExtractServiceDescription() ([]ServiceDescription, error) {
+	// schedules of services that were never registered would otherwise be dropped silently
+	for srvName := range serviceSchedules {
+		if _, err := getService(srvName); err != nil {
+			return nil, err
+		}
+	}
+
 	var services []ServiceDescription
 	for srvName, srv := range serviceMap {
+		schedules, err := loadSchedules(srvName)
+		if err != nil {
+			return nil, err
+		}
+
 		serviceData := ServiceDescription{
-			Name:  srvName,
-			Tasks: make([]MethodDescription, 0),
+			Name:       srvName,
+			Tasks:      make([]MethodDescription, 0),
+			Schedules:  schedules,
+			DeadLetter: serviceDeadLetters[srvName],
 		}
 
 		res, err := srv.ExecuteService(nil, "@definition", nil)
@@ -0,0 +1,26 @@
+package polycode
+
+import "fmt"
+
+// DefaultVersion is returned by GetVersion for executions that started before the change was added
+const DefaultVersion = -1
+
+func getVersion(sessionId string, serviceClient *ServiceClient, changeId string, minVersion int, maxVersion int) (int, error) {
+	req := GetVersionRequest{
+		ChangeId:   changeId,
+		MinVersion: minVersion,
+		MaxVersion: maxVersion,
+	}
+
+	res, err := serviceClient.GetVersion(sessionId, req)
+	if err != nil {
+		fmt.Printf("client: get version error: %v\n", err)
+		return DefaultVersion, wrapTaskError(err)
+	}
+
+	if res.Version < minVersion || res.Version > maxVersion {
+		return res.Version, ErrVersionOutOfRange.With(res.Version, changeId, minVersion, maxVersion)
+	}
+
+	return res.Version, nil
+}
@@ -0,0 +1,90 @@
+package polycode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type QueryHandler func(input any) (any, error)
+
+// queryRegistry holds the query handlers of workflows currently running in this process, keyed by task id.
+// Handlers are invoked from the api server goroutine, so they must be safe to call concurrently with the workflow.
+type queryRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]map[string]QueryHandler
+}
+
+var workflowQueries = &queryRegistry{handlers: make(map[string]map[string]QueryHandler)}
+
+var queryReplaySeq atomic.Uint64
+
+// queryReplayKey returns a registry key unique to one query replay of taskId
+func queryReplayKey(taskId string) string {
+	return fmt.Sprintf("%s@query:%d", taskId, queryReplaySeq.Add(1))
+}
+
+func (q *queryRegistry) set(taskId string, name string, handler QueryHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.handlers[taskId] == nil {
+		q.handlers[taskId] = make(map[string]QueryHandler)
+	}
+	q.handlers[taskId][name] = handler
+}
+
+func (q *queryRegistry) get(taskId string, name string) QueryHandler {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.handlers[taskId][name]
+}
+
+func (q *queryRegistry) remove(taskId string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.handlers, taskId)
+}
+
+func runQuery(ctx context.Context, taskLogger Logger, event QueryStartEvent) (evt QueryCompleteEvent) {
+	taskLogger.Info().Msg(fmt.Sprintf("query started %s on task %s", event.Query, event.TaskId))
+
+	defer func() {
+		if r := recover(); r != nil {
+			errorStr := fmt.Sprintf("recoverted %v", r)
+			taskLogger.Error().Msg(errorStr)
+			evt = QueryCompleteEvent{
+				IsError: true,
+				Error:   ErrInternal.Wrap(fmt.Errorf("%s", errorStr)),
+			}
+		}
+	}()
+
+	handler := workflowQueries.get(event.TaskId, event.Query)
+	if handler == nil {
+		err := ErrQueryNotFound.With(event.Query, event.TaskId)
+		taskLogger.Error().Msg(err.Error())
+		return QueryCompleteEvent{
+			IsError: true,
+			Error:   err,
+		}
+	}
+
+	output, err := handler(event.Input)
+	if err != nil {
+		err2 := ErrServiceExecError.Wrap(err)
+		taskLogger.Error().Msg(err2.Error())
+		return QueryCompleteEvent{
+			IsError: true,
+			Error:   err2,
+		}
+	}
+
+	taskLogger.Info().Msg("query completed")
+	return QueryCompleteEvent{
+		Output: output,
+	}
+}